@@ -3,15 +3,26 @@ package config
 import (
 	"fmt"
 	"go-billing-engine/models"
+	"go-billing-engine/utils"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
+var LoginAttempts utils.LoginAttemptStore
+var Mailer utils.Mailer
+var CollectionProvider utils.CollectionProvider
+var DebitRetrySchedule []time.Duration
+var DueDateAdjustment string
+var DocumentStore utils.DocumentStore
+var RequireVerifiedKYC bool
 
 func LoadEnv() error {
 	err := godotenv.Load()
@@ -45,14 +56,181 @@ func ConnectDatabase() {
 	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Pricing{},
+		&models.Product{},
+		&models.PricingTier{},
+		&models.PromoCode{},
+		&models.PromoRedemption{},
+		&models.Holiday{},
 		&models.Loan{},
+		&models.LoanParty{},
+		&models.BorrowerProfile{},
+		&models.KYCDocument{},
+		&models.CreditRuleVersion{},
+		&models.CreditDecision{},
 		&models.Installment{},
 		&models.Payment{},
+		&models.LoginAttempt{},
+		&models.LoginLockoutEvent{},
+		&models.RecoveryCode{},
+		&models.UserToken{},
+		&models.APIClient{},
+		&models.PaymentPartner{},
+		&models.SignedRequest{},
+		&models.VirtualAccount{},
+		&models.VANotification{},
+		&models.BankStatement{},
+		&models.BankStatementLine{},
+		&models.CreditBalance{},
+		&models.CreditTransaction{},
+		&models.CreditRefund{},
+		&models.DebitMandate{},
+		&models.DebitAttempt{},
+		&models.ScheduleVersion{},
+		&models.LoanRestructure{},
+		&models.LoanWriteOff{},
+		&models.LoanTopUp{},
+		&models.LoanRecovery{},
+		&models.CreditLimit{},
+		&models.CreditLine{},
+		&models.Drawdown{},
+		&models.CreditLineTransaction{},
+		&models.CreditLineStatement{},
 	); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
 	DB = db
+
+	SetupLoginAttemptStore()
+	SetupMailer()
+	SetupCollections()
+	SetupHolidays()
+	SetupKYC()
+}
+
+func SetupLoginAttemptStore() {
+	switch getEnv("LOGIN_ATTEMPT_STORE", "postgres") {
+	case "memory":
+		LoginAttempts = utils.NewMemoryLoginAttemptStore()
+	default:
+		LoginAttempts = utils.NewPostgresLoginAttemptStore(DB)
+	}
+}
+
+func SetupMailer() {
+	switch getEnv("MAIL_DRIVER", "log") {
+	case "smtp":
+		Mailer = utils.NewSMTPMailer(
+			getEnv("SMTP_HOST", "localhost"),
+			getEnv("SMTP_PORT", "587"),
+			getEnv("SMTP_USER", ""),
+			getEnv("SMTP_PASS", ""),
+			getEnv("MAIL_FROM", "no-reply@go-billing-engine.local"),
+		)
+	default:
+		Mailer = utils.NewLogMailer(getEnv("MAIL_LOG_PATH", ""))
+	}
+}
+
+func SetupCollections() {
+	switch provider := getEnv("COLLECTION_PROVIDER", "fake"); provider {
+	case "fake":
+		CollectionProvider = utils.NewFakeCollectionProvider()
+	default:
+		log.Fatal("Unknown COLLECTION_PROVIDER:", provider)
+	}
+
+	DebitRetrySchedule = nil
+	for _, entry := range strings.Split(getEnv("DEBIT_RETRY_SCHEDULE", "24h,72h"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		offset, err := time.ParseDuration(entry)
+		if err != nil {
+			log.Fatal("Invalid DEBIT_RETRY_SCHEDULE entry:", entry)
+		}
+		DebitRetrySchedule = append(DebitRetrySchedule, offset)
+	}
+}
+
+// SetupHolidays reads the default due date adjustment and, when HOLIDAY_FILE
+// is set, loads the holidays listed there. Dates already in the calendar keep
+// their current name so edits made through the admin API are not undone.
+func SetupHolidays() {
+	DueDateAdjustment = strings.ToUpper(getEnv("DUE_DATE_ADJUSTMENT", "FOLLOWING"))
+	if !utils.ValidDateAdjustment(DueDateAdjustment) {
+		log.Fatal("Invalid DUE_DATE_ADJUSTMENT:", DueDateAdjustment)
+	}
+
+	path := getEnv("HOLIDAY_FILE", "")
+	if path == "" {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal("Failed to open HOLIDAY_FILE:", err)
+	}
+	defer file.Close()
+
+	entries, err := utils.ParseHolidayFile(file)
+	if err != nil {
+		log.Fatal("Failed to parse HOLIDAY_FILE:", err)
+	}
+
+	for _, entry := range entries {
+		holiday := models.Holiday{
+			HolidayDate: entry.Date,
+			HolidayName: entry.Name,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if err := DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "holiday_date"}},
+			DoNothing: true,
+		}).Create(&holiday).Error; err != nil {
+			log.Fatal("Failed to load holidays:", err)
+		}
+	}
+	log.Printf("Loaded %d holidays from %s", len(entries), path)
+}
+
+func SetupKYC() {
+	switch getEnv("DOCUMENT_STORE", "local") {
+	default:
+		DocumentStore = utils.NewLocalDocumentStore(getEnv("DOCUMENT_STORE_PATH", "storage/documents"))
+	}
+
+	RequireVerifiedKYC = getEnv("REQUIRE_VERIFIED_KYC", "true") == "true"
+}
+
+// CollectionSchedulerInterval returns how often the collection cycle runs, or
+// zero when the scheduler is disabled.
+func CollectionSchedulerInterval() time.Duration {
+	interval, err := time.ParseDuration(getEnv("COLLECTION_SCHEDULER_INTERVAL", "0"))
+	if err != nil {
+		log.Println("Invalid COLLECTION_SCHEDULER_INTERVAL, scheduler disabled")
+		return 0
+	}
+	return interval
+}
+
+// CreditRulesFile returns the path of the credit rule set loaded at start-up,
+// or an empty string when rules are managed only through the admin API.
+func CreditRulesFile() string {
+	return getEnv("CREDIT_RULES_FILE", "")
+}
+
+// TrustedProxies returns the proxies whose X-Forwarded-For header is believed
+// when working out a client's IP. By default none are trusted and the
+// connection's remote address is used.
+func TrustedProxies() []string {
+	return utils.SplitList(getEnv("TRUSTED_PROXIES", ""))
+}
+
+func AppBaseURL() string {
+	return getEnv("APP_BASE_URL", "http://localhost:8080")
 }
 
 func getEnv(key, fallback string) string {
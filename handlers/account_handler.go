@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	tokenPurposeEmailVerification = "EMAIL_VERIFICATION"
+	tokenPurposePasswordReset     = "PASSWORD_RESET"
+
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+var errInvalidUserToken = errors.New("invalid or expired token")
+
+func VerifyEmail(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	userToken, err := consumeUserToken(tx, tokenPurposeEmailVerification, input.Token, time.Now())
+	if err != nil {
+		tx.Rollback()
+		respondUserTokenError(c, err)
+		return
+	}
+
+	if err := activateUser(tx, userToken.UserID, time.Now()); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email address"})
+		return
+	}
+
+	tx.Commit()
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified successfully"})
+}
+
+func ResendVerificationEmail(c *gin.Context) {
+	var input struct {
+		EmailAddress string `json:"email_address" binding:"required,email"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	err := config.DB.Where("email_address = ?", strings.ToLower(input.EmailAddress)).First(&user).Error
+	if err == nil && user.UserStatus == "PENDING_VERIFICATION" {
+		if err := sendUserTokenEmail(user, tokenPurposeEmailVerification); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account is awaiting verification, a new email has been sent"})
+}
+
+func ForgotPassword(c *gin.Context) {
+	var input struct {
+		EmailAddress string `json:"email_address" binding:"required,email"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The response is the same whatever happens, so it cannot be used to find
+	// out which addresses are registered.
+	var user models.User
+	err := config.DB.Where("email_address = ?", strings.ToLower(input.EmailAddress)).First(&user).Error
+	switch {
+	case err == nil:
+		if err := sendUserTokenEmail(user, tokenPurposePasswordReset); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Printf("Failed to look up user for password reset: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the email address is registered, a password reset link has been sent"})
+}
+
+func ResetPassword(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	salt, err := utils.GenerateRandomSalt()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate salt"})
+		return
+	}
+
+	now := time.Now()
+	tx := config.DB.Begin()
+
+	userToken, err := consumeUserToken(tx, tokenPurposePasswordReset, input.Token, now)
+	if err != nil {
+		tx.Rollback()
+		respondUserTokenError(c, err)
+		return
+	}
+
+	var user models.User
+	if err := tx.First(&user, userToken.UserID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	user.PasswordSalt = salt
+	user.PasswordHash = utils.HashPassword(input.Password, salt)
+	user.UpdatedAt = now
+
+	if err := tx.Save(&user).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	if err := revokeUserTokens(tx, user.ID, tokenPurposePasswordReset, now); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke reset tokens"})
+		return
+	}
+
+	// Receiving the reset email proves ownership of the address as well.
+	if user.UserStatus == "PENDING_VERIFICATION" {
+		if err := activateUser(tx, user.ID, now); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email address"})
+			return
+		}
+	}
+
+	tx.Commit()
+
+	if err := config.LoginAttempts.Reset(accountAttemptKey(user.EmailAddress)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+func sendUserTokenEmail(user models.User, purpose string) error {
+	now := time.Now()
+	tx := config.DB.Begin()
+
+	if err := revokeUserTokens(tx, user.ID, purpose, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	token, err := issueUserToken(tx, user.ID, purpose, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return config.Mailer.Send(userTokenMessage(user, purpose, token))
+}
+
+func issueUserToken(tx *gorm.DB, userID uint64, purpose string, now time.Time) (string, error) {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	ttl := emailVerificationTTL
+	if purpose == tokenPurposePasswordReset {
+		ttl = passwordResetTTL
+	}
+
+	userToken := models.UserToken{
+		UserID:       userID,
+		TokenPurpose: purpose,
+		TokenHash:    utils.HashToken(token),
+		ExpiresAt:    now.Add(ttl),
+		CreatedAt:    now,
+	}
+
+	if err := tx.Create(&userToken).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeUserToken marks a token as used with a conditional update so two
+// concurrent requests cannot both redeem it.
+func consumeUserToken(tx *gorm.DB, purpose, token string, now time.Time) (models.UserToken, error) {
+	var userToken models.UserToken
+	if err := tx.Where("token_hash = ? AND token_purpose = ?", utils.HashToken(token), purpose).First(&userToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return userToken, errInvalidUserToken
+		}
+		return userToken, err
+	}
+
+	if userToken.UsedAt != nil || !now.Before(userToken.ExpiresAt) {
+		return userToken, errInvalidUserToken
+	}
+
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", userToken.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return userToken, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return userToken, errInvalidUserToken
+	}
+
+	return userToken, nil
+}
+
+func revokeUserTokens(tx *gorm.DB, userID uint64, purpose string, now time.Time) error {
+	return tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND token_purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error
+}
+
+func activateUser(tx *gorm.DB, userID uint64, now time.Time) error {
+	return tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"user_status":       "ACTIVE",
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).Error
+}
+
+func respondUserTokenError(c *gin.Context, err error) {
+	if errors.Is(err, errInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate token"})
+}
+
+func userTokenMessage(user models.User, purpose, token string) utils.MailMessage {
+	if purpose == tokenPurposePasswordReset {
+		return utils.MailMessage{
+			To:      user.EmailAddress,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf(
+				"Hello %s,\n\nUse the link below to reset your password. It expires in %d minutes.\n\n%s/reset-password?token=%s\n\nIf you did not request this, you can ignore this email.",
+				user.FullName, int(passwordResetTTL.Minutes()), config.AppBaseURL(), token,
+			),
+		}
+	}
+
+	return utils.MailMessage{
+		To:      user.EmailAddress,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the link below to verify your email address. It expires in %d hours.\n\n%s/verify-email?token=%s",
+			user.FullName, int(emailVerificationTTL.Hours()), config.AppBaseURL(), token,
+		),
+	}
+}
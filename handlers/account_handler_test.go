@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type captureMailer struct {
+	sent []utils.MailMessage
+	err  error
+}
+
+func (m *captureMailer) Send(message utils.MailMessage) error {
+	m.sent = append(m.sent, message)
+	return m.err
+}
+
+func stubMailer(t *testing.T, mailer utils.Mailer) {
+	t.Helper()
+
+	previous := config.Mailer
+	config.Mailer = mailer
+	t.Cleanup(func() { config.Mailer = previous })
+}
+
+func TestForgotPasswordRespondsTheSame(t *testing.T) {
+	const want = `{"message":"If the email address is registered, a password reset link has been sent"}`
+
+	tests := []struct {
+		name     string
+		lookup   func(mock sqlmock.Sqlmock)
+		mailErr  error
+		wantSent int
+	}{
+		{
+			name: "registered",
+			lookup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "users"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "email_address"}).AddRow(5, "borrower@example.com"))
+			},
+			wantSent: 1,
+		},
+		{
+			name: "registered but the mail fails",
+			lookup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "users"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "email_address"}).AddRow(5, "borrower@example.com"))
+			},
+			mailErr:  errors.New("smtp unavailable"),
+			wantSent: 1,
+		},
+		{
+			name: "not registered",
+			lookup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+		{
+			name: "lookup fails",
+			lookup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM "users"`).WillReturnError(errors.New("connection reset"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			mailer := &captureMailer{err: tt.mailErr}
+			stubMailer(t, mailer)
+
+			tt.lookup(mock)
+			if tt.wantSent > 0 {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user_tokens" SET "used_at"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO "user_tokens"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+				mock.ExpectCommit()
+			}
+
+			w := serve(http.MethodPost, "/forgot", "/forgot", `{"email_address":"Borrower@Example.com"}`, nil, ForgotPassword)
+
+			if w.Code != http.StatusOK || w.Body.String() != want {
+				t.Fatalf("response = %d %s, want 200 %s", w.Code, w.Body, want)
+			}
+			if len(mailer.sent) != tt.wantSent {
+				t.Fatalf("sent %d emails, want %d", len(mailer.sent), tt.wantSent)
+			}
+			if tt.wantSent > 0 && !strings.Contains(mailer.sent[0].Body, "/reset-password?token=") {
+				t.Errorf("reset email has no reset link: %q", mailer.sent[0].Body)
+			}
+		})
+	}
+}
+
+func TestConsumeUserToken(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	used := now.Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		found     bool
+		expiresAt time.Time
+		usedAt    *time.Time
+		// consumed is what the conditional used_at update reports; zero
+		// means another request redeemed the token first.
+		consumed int64
+		wantErr  error
+	}{
+		{name: "valid", found: true, expiresAt: now.Add(time.Minute), consumed: 1},
+		{name: "unknown token", wantErr: errInvalidUserToken},
+		{name: "expired", found: true, expiresAt: now.Add(-time.Second), wantErr: errInvalidUserToken},
+		{name: "expires this instant", found: true, expiresAt: now, wantErr: errInvalidUserToken},
+		{name: "already used", found: true, expiresAt: now.Add(time.Minute), usedAt: &used, wantErr: errInvalidUserToken},
+		{name: "redeemed concurrently", found: true, expiresAt: now.Add(time.Minute), consumed: 0, wantErr: errInvalidUserToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+
+			rows := sqlmock.NewRows([]string{"id", "user_id", "token_purpose", "expires_at", "used_at"})
+			if tt.found {
+				rows.AddRow(9, 5, tokenPurposePasswordReset, tt.expiresAt, tt.usedAt)
+			}
+			mock.ExpectQuery(`FROM "user_tokens" WHERE token_hash = \$1 AND token_purpose = \$2`).
+				WithArgs(utils.HashToken("reset-token"), tokenPurposePasswordReset, 1).
+				WillReturnRows(rows)
+			if tt.found && tt.usedAt == nil && now.Before(tt.expiresAt) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "user_tokens" SET "used_at"=\$1 WHERE id = \$2 AND used_at IS NULL`).
+					WithArgs(now, 9).
+					WillReturnResult(sqlmock.NewResult(0, tt.consumed))
+				mock.ExpectCommit()
+			}
+
+			token, err := consumeUserToken(config.DB, tokenPurposePasswordReset, "reset-token", now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("consumeUserToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && token.UserID != 5 {
+				t.Errorf("consumeUserToken() user = %d, want 5", token.UserID)
+			}
+		})
+	}
+}
+
+func TestResetPasswordSingleUse(t *testing.T) {
+	mock := mockDB(t)
+
+	attempts := config.LoginAttempts
+	config.LoginAttempts = utils.NewMemoryLoginAttemptStore()
+	t.Cleanup(func() { config.LoginAttempts = attempts })
+
+	tokenColumns := []string{"id", "user_id", "token_purpose", "expires_at", "used_at"}
+	expiresAt := time.Now().Add(time.Hour)
+
+	// First use: the token is consumed, the password saved and every other
+	// reset token revoked.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM "user_tokens"`).
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow(9, 5, tokenPurposePasswordReset, expiresAt, nil))
+	mock.ExpectExec(`UPDATE "user_tokens" SET "used_at"=\$1 WHERE id = \$2 AND used_at IS NULL`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM "users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email_address", "user_status"}).AddRow(5, "borrower@example.com", "ACTIVE"))
+	mock.ExpectExec(`UPDATE "users"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "user_tokens" SET "used_at"=\$1 WHERE user_id = \$2 AND token_purpose = \$3 AND used_at IS NULL`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// Second use: the stored token is now marked used.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM "user_tokens"`).
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow(9, 5, tokenPurposePasswordReset, expiresAt, time.Now()))
+	mock.ExpectRollback()
+
+	body := `{"token":"reset-token","password":"new-secret"}`
+
+	if w := serve(http.MethodPost, "/reset", "/reset", body, nil, ResetPassword); w.Code != http.StatusOK {
+		t.Fatalf("first reset = %d %s, want 200", w.Code, w.Body)
+	}
+	if w := serve(http.MethodPost, "/reset", "/reset", body, nil, ResetPassword); w.Code != http.StatusBadRequest {
+		t.Fatalf("second reset = %d %s, want 400", w.Code, w.Body)
+	}
+}
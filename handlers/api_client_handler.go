@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateAPIClient(c *gin.Context) {
+	var input struct {
+		ClientName string   `json:"client_name" binding:"required"`
+		Scopes     []string `json:"scopes" binding:"required,min=1"`
+		AllowedIPs []string `json:"allowed_ips"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range input.Scopes {
+		if !utils.ValidAPIScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+	}
+
+	for _, entry := range input.AllowedIPs {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid IP address or CIDR: " + entry})
+			return
+		}
+	}
+
+	apiKey, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	client := models.APIClient{
+		ClientName:   input.ClientName,
+		KeyPrefix:    prefix,
+		KeyHash:      utils.HashToken(apiKey),
+		Scopes:       strings.Join(input.Scopes, ","),
+		AllowedIPs:   strings.Join(input.AllowedIPs, ","),
+		ClientStatus: "ACTIVE",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := config.DB.Create(&client).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API client"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "API client created successfully, store the key now as it will not be shown again",
+		"api_client": client,
+		"api_key":    apiKey,
+	})
+}
+
+func GetAllAPIClients(c *gin.Context) {
+	var clients []models.APIClient
+	if err := config.DB.Order("created_at desc").Find(&clients).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API clients"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "API clients fetched successfully",
+		"api_clients": clients,
+	})
+}
+
+func RevokeAPIClient(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var client models.APIClient
+	if err := config.DB.First(&client, clientID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API client not found"})
+		return
+	}
+
+	client.ClientStatus = "REVOKED"
+	client.UpdatedAt = time.Now()
+
+	if err := config.DB.Save(&client).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API client"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "API client revoked successfully",
+		"api_client": client,
+	})
+}
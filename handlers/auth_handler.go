@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"go-billing-engine/config"
 	"go-billing-engine/models"
@@ -41,6 +45,7 @@ func Register(c *gin.Context) {
 		EmailAddress: strings.ToLower(input.EmailAddress),
 		PasswordHash: passwordHash,
 		PasswordSalt: salt,
+		UserStatus:   "PENDING_VERIFICATION",
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
@@ -48,7 +53,12 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	if err := sendUserTokenEmail(user, tokenPurposeEmailVerification); err != nil {
+		c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully, but the verification email could not be sent"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully, please check your email to verify your account"})
 }
 
 func Login(c *gin.Context) {
@@ -62,17 +72,60 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	emailAddress := strings.ToLower(input.EmailAddress)
+	ipAddress := c.ClientIP()
+	now := time.Now()
+
+	retryAfter, err := loginRetryAfter(emailAddress, ipAddress, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return
+	}
+	if retryAfter > 0 {
+		tooManyLoginAttempts(c, retryAfter)
+		return
+	}
+
 	var user models.User
-	if err := config.DB.Where("email_address = ?", strings.ToLower(input.EmailAddress)).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	if err := config.DB.Where("email_address = ?", emailAddress).First(&user).Error; err != nil {
+		rejectLogin(c, emailAddress, ipAddress, now)
 		return
 	}
 
 	if !utils.CheckPassword(input.Password, user.PasswordSalt, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		rejectLogin(c, emailAddress, ipAddress, now)
+		return
+	}
+
+	if err := config.LoginAttempts.Reset(accountAttemptKey(emailAddress)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset login attempts"})
+		return
+	}
+
+	if user.UserStatus == "PENDING_VERIFICATION" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email address has not been verified"})
 		return
 	}
 
+	if user.TOTPEnabled {
+		mfaToken, err := utils.GenerateMFAToken(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Two-factor authentication required",
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
+		return
+	}
+
+	respondLoginSuccess(c, user)
+}
+
+func respondLoginSuccess(c *gin.Context, user models.User) {
 	token, err := utils.GenerateJWT(user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -89,3 +142,80 @@ func Login(c *gin.Context) {
 		"token": token,
 	})
 }
+
+func tooManyLoginAttempts(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": fmt.Sprintf("Too many failed login attempts, try again in %d seconds", seconds),
+	})
+}
+
+func accountAttemptKey(emailAddress string) string {
+	return "account:" + emailAddress
+}
+
+func ipAttemptKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+func loginRetryAfter(emailAddress, ipAddress string, now time.Time) (time.Duration, error) {
+	accountRecord, err := config.LoginAttempts.Get(accountAttemptKey(emailAddress))
+	if err != nil {
+		return 0, err
+	}
+
+	ipRecord, err := config.LoginAttempts.Get(ipAttemptKey(ipAddress))
+	if err != nil {
+		return 0, err
+	}
+
+	accountWait := utils.LoginRetryAfter(accountRecord, utils.AccountLoginPolicy, now)
+	ipWait := utils.LoginRetryAfter(ipRecord, utils.IPLoginPolicy, now)
+	if ipWait > accountWait {
+		return ipWait, nil
+	}
+	return accountWait, nil
+}
+
+func rejectLogin(c *gin.Context, emailAddress, ipAddress string, now time.Time) {
+	if err := recordLoginFailure("ACCOUNT", accountAttemptKey(emailAddress), utils.AccountLoginPolicy, emailAddress, ipAddress, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record login attempt"})
+		return
+	}
+
+	if err := recordLoginFailure("IP", ipAttemptKey(ipAddress), utils.IPLoginPolicy, emailAddress, ipAddress, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record login attempt"})
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+}
+
+func recordLoginFailure(scope, key string, policy utils.LoginThrottlePolicy, emailAddress, ipAddress string, now time.Time) error {
+	record, err := config.LoginAttempts.RecordFailure(key, now, policy.Window)
+	if err != nil {
+		return err
+	}
+
+	if !utils.ShouldLockLogin(record, policy) {
+		return nil
+	}
+
+	lockedUntil := now.Add(policy.LockoutDuration)
+	if err := config.LoginAttempts.Lock(key, lockedUntil); err != nil {
+		return err
+	}
+
+	event := models.LoginLockoutEvent{
+		LockoutScope: scope,
+		AttemptKey:   key,
+		EmailAddress: emailAddress,
+		IPAddress:    ipAddress,
+		Failures:     record.Failures,
+		LockedUntil:  lockedUntil,
+		CreatedAt:    now,
+	}
+
+	return config.DB.Create(&event).Error
+}
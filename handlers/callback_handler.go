@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func PaymentCallback(c *gin.Context) {
+	partner := c.MustGet("payment_partner").(models.PaymentPartner)
+
+	var input struct {
+		LoanCode      string  `json:"loan_code" binding:"required"`
+		PaymentAmount float64 `json:"payment_amount" binding:"required,gt=0"`
+		Reference     string  `json:"reference" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if existingPayment, ok := findCallbackPayment(config.DB, partner.PartnerCode, input.Reference); ok {
+		respondPaymentReplay(c, existingPayment)
+		return
+	}
+
+	var loan models.Loan
+	if err := config.DB.Where("loan_code = ?", input.LoanCode).First(&loan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	// Redeliveries of the same payment queue on the loan lock, so the check
+	// is repeated once it is held to see a payment the first one committed.
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loan.ID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+	if existingPayment, ok := findCallbackPayment(tx, partner.PartnerCode, input.Reference); ok {
+		tx.Rollback()
+		respondPaymentReplay(c, existingPayment)
+		return
+	}
+
+	payment, err := postPayment(tx, paymentPosting{
+		LoanID:            loan.ID,
+		PaymentAmount:     input.PaymentAmount,
+		PaymentChannel:    "CALLBACK",
+		PartnerCode:       partner.PartnerCode,
+		ExternalReference: input.Reference,
+	})
+	if err != nil {
+		tx.Rollback()
+		// A delivery for the same reference on another loan can still win the
+		// race to the unique index; that loser replays the winner's payment.
+		if existingPayment, ok := findCallbackPayment(config.DB, partner.PartnerCode, input.Reference); ok {
+			respondPaymentReplay(c, existingPayment)
+			return
+		}
+		respondPostingError(c, err)
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment processed successfully",
+		"payment": payment,
+	})
+}
+
+func findCallbackPayment(db *gorm.DB, partnerCode, reference string) (models.Payment, bool) {
+	var payment models.Payment
+	err := db.Where("partner_code = ? AND external_reference = ?", partnerCode, reference).First(&payment).Error
+	return payment, err == nil
+}
+
+func respondPaymentReplay(c *gin.Context, payment models.Payment) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment already processed",
+		"payment": payment,
+	})
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type collectionSummary struct {
+	CreditsApplied   int `json:"credits_applied"`
+	Scheduled        int `json:"scheduled"`
+	Succeeded        int `json:"succeeded"`
+	Failed           int `json:"failed"`
+	Exhausted        int `json:"exhausted"`
+	Cancelled        int `json:"cancelled"`
+	Unknown          int `json:"unknown"`
+	Unposted         int `json:"unposted"`
+	StatementsIssued int `json:"statements_issued"`
+}
+
+// StartCollectionScheduler runs the collection cycle on a fixed interval in
+// the background. A zero interval leaves the scheduler off.
+func StartCollectionScheduler(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			summary, err := runCollectionCycle(now)
+			if err != nil {
+				log.Println("Collection cycle failed:", err)
+				continue
+			}
+			log.Printf("Collection cycle finished: %+v", summary)
+		}
+	}()
+}
+
+// runCollectionCycle applies credit balances first, then schedules a debit for
+// each installment due by asOf that is covered by an active mandate, and
+// executes every attempt whose time has come. Credit line statements that
+// have fallen due are issued last.
+func runCollectionCycle(asOf time.Time) (collectionSummary, error) {
+	var summary collectionSummary
+
+	applied, err := sweepCreditBalances(asOf)
+	if err != nil {
+		return summary, err
+	}
+	summary.CreditsApplied = len(applied)
+
+	scheduled, err := scheduleDebitAttempts(asOf)
+	if err != nil {
+		return summary, err
+	}
+	summary.Scheduled = scheduled
+
+	var attempts []models.DebitAttempt
+	if err := config.DB.
+		Where("attempt_status = ? AND scheduled_at <= ?", "SCHEDULED", asOf).
+		Order("scheduled_at asc").
+		Find(&attempts).Error; err != nil {
+		return summary, err
+	}
+
+	for _, attempt := range attempts {
+		status, err := executeDebitAttempt(attempt.ID, asOf)
+		if status == "UNPOSTED" {
+			// The debit itself went through; carry on with the rest of the
+			// cycle and leave the posting to reconciliation.
+			log.Printf("Debit attempt %d succeeded but could not be posted: %v", attempt.ID, err)
+			summary.Unposted++
+			continue
+		}
+		if err != nil {
+			return summary, err
+		}
+
+		switch status {
+		case "SUCCEEDED":
+			summary.Succeeded++
+		case "FAILED":
+			summary.Failed++
+		case "EXHAUSTED":
+			summary.Exhausted++
+		case "CANCELLED":
+			summary.Cancelled++
+		case "UNKNOWN":
+			summary.Unknown++
+		}
+	}
+
+	issued, err := runStatementCycle(asOf)
+	if err != nil {
+		return summary, err
+	}
+	summary.StatementsIssued = issued
+
+	return summary, nil
+}
+
+func scheduleDebitAttempts(asOf time.Time) (int, error) {
+	endOfDay := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 23, 59, 59, 0, asOf.Location())
+
+	var installments []models.Installment
+	if err := config.DB.
+		Where("paid_status = ? AND due_date <= ?", "PENDING", endOfDay).
+		Where("loan_id IN (?)", config.DB.Model(&models.Loan{}).Select("id").Where("loan_status = ?", "ACTIVE")).
+		Where("id NOT IN (?)", config.DB.Model(&models.DebitAttempt{}).Select("installment_id")).
+		Order("due_date asc").
+		Find(&installments).Error; err != nil {
+		return 0, err
+	}
+
+	scheduled := 0
+	for _, inst := range installments {
+		var mandate models.DebitMandate
+		err := config.DB.
+			Where("user_id = ? AND mandate_status = ?", inst.UserID, "ACTIVE").
+			Where("loan_id = ? OR loan_id IS NULL", inst.LoanID).
+			Order("loan_id IS NULL, created_at desc").
+			First(&mandate).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return scheduled, err
+		}
+
+		amount := utils.RoundFloat((inst.PrincipalAmount-inst.PaidAmountPrincipal)+(inst.InterestAmount-inst.PaidAmountInterest), 2)
+		if amount > mandate.MaxAmount {
+			amount = mandate.MaxAmount
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		attempt := models.DebitAttempt{
+			MandateID:     mandate.ID,
+			LoanID:        inst.LoanID,
+			InstallmentID: inst.ID,
+			AttemptNumber: 1,
+			Amount:        amount,
+			AttemptStatus: "SCHEDULED",
+			ScheduledAt:   asOf,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&attempt)
+		if result.Error != nil {
+			return scheduled, result.Error
+		}
+		scheduled += int(result.RowsAffected)
+	}
+
+	return scheduled, nil
+}
+
+// executeDebitAttempt sends one attempt to the provider and posts the money
+// through postPayment when it succeeds. A failed attempt schedules the next
+// retry from config.DebitRetrySchedule until the schedule is used up.
+//
+// The attempt is claimed as IN_PROGRESS and committed before the provider is
+// called, so no lock is held during the call and a concurrent run cannot send
+// it again. A provider error leaves the outcome unknown, and a debit that
+// went through but could not be posted has taken money that is not on the
+// books yet; both are parked as UNKNOWN or UNPOSTED for reconciliation and
+// are never retried automatically.
+func executeDebitAttempt(attemptID uint64, asOf time.Time) (string, error) {
+	attempt, mandate, err := claimDebitAttempt(attemptID)
+	if err != nil || attempt.AttemptStatus != "IN_PROGRESS" {
+		return attempt.AttemptStatus, err
+	}
+
+	result, err := config.CollectionProvider.Debit(utils.DebitRequest{
+		Reference:     fmt.Sprintf("DEBIT-%d", attempt.ID),
+		BankCode:      mandate.BankCode,
+		AccountNumber: mandate.AccountNumber,
+		AccountName:   mandate.AccountName,
+		Amount:        attempt.Amount,
+	})
+	if err != nil {
+		return "UNKNOWN", parkDebitAttempt(attempt.ID, "UNKNOWN", "", "Provider error: "+err.Error())
+	}
+
+	if !result.Succeeded {
+		return failDebitAttempt(attempt, result, asOf)
+	}
+
+	status, err := settleDebitAttempt(attempt, mandate, result)
+	if err != nil {
+		if parkErr := parkDebitAttempt(attempt.ID, "UNPOSTED", result.ProviderRef, "Posting failed: "+err.Error()); parkErr != nil {
+			log.Printf("Failed to park debit attempt %d: %v", attempt.ID, parkErr)
+		}
+		return "UNPOSTED", err
+	}
+	return status, nil
+}
+
+// claimDebitAttempt moves a SCHEDULED attempt to IN_PROGRESS, or cancels it
+// when its mandate or installment no longer needs collecting. Any other status
+// is returned unchanged for the caller to skip.
+func claimDebitAttempt(attemptID uint64) (models.DebitAttempt, models.DebitMandate, error) {
+	var mandate models.DebitMandate
+	tx := config.DB.Begin()
+
+	var attempt models.DebitAttempt
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&attempt, attemptID).Error; err != nil {
+		tx.Rollback()
+		return attempt, mandate, err
+	}
+	if attempt.AttemptStatus != "SCHEDULED" {
+		tx.Rollback()
+		return attempt, mandate, nil
+	}
+
+	if err := tx.First(&mandate, attempt.MandateID).Error; err != nil {
+		tx.Rollback()
+		return attempt, mandate, err
+	}
+
+	var installment models.Installment
+	if err := tx.First(&installment, attempt.InstallmentID).Error; err != nil {
+		tx.Rollback()
+		return attempt, mandate, err
+	}
+
+	now := time.Now()
+	attempt.AttemptedAt = &now
+	attempt.UpdatedAt = now
+	attempt.AttemptStatus = "IN_PROGRESS"
+
+	if mandate.MandateStatus != "ACTIVE" || installment.PaidStatus != "PENDING" {
+		attempt.AttemptStatus = "CANCELLED"
+		attempt.FailureReason = "Mandate inactive or installment already paid"
+	}
+
+	if err := tx.Save(&attempt).Error; err != nil {
+		tx.Rollback()
+		return attempt, mandate, err
+	}
+	return attempt, mandate, tx.Commit().Error
+}
+
+// failDebitAttempt records a declined debit and schedules the next retry.
+func failDebitAttempt(attempt models.DebitAttempt, result utils.DebitResult, asOf time.Time) (string, error) {
+	tx := config.DB.Begin()
+
+	now := time.Now()
+	attempt.ProviderRef = result.ProviderRef
+	attempt.FailureReason = result.FailureReason
+	attempt.AttemptStatus = "FAILED"
+	attempt.UpdatedAt = now
+
+	if attempt.AttemptNumber <= len(config.DebitRetrySchedule) {
+		retry := models.DebitAttempt{
+			MandateID:     attempt.MandateID,
+			LoanID:        attempt.LoanID,
+			InstallmentID: attempt.InstallmentID,
+			AttemptNumber: attempt.AttemptNumber + 1,
+			Amount:        attempt.Amount,
+			AttemptStatus: "SCHEDULED",
+			ScheduledAt:   firstAttemptTime(tx, attempt, asOf).Add(config.DebitRetrySchedule[attempt.AttemptNumber-1]),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&retry).Error; err != nil {
+			tx.Rollback()
+			return "", err
+		}
+	} else {
+		attempt.AttemptStatus = "EXHAUSTED"
+	}
+
+	if err := tx.Save(&attempt).Error; err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	return attempt.AttemptStatus, tx.Commit().Error
+}
+
+// settleDebitAttempt posts the money from a successful debit against the loan,
+// or to the borrower's credit balance when the loan cannot accept it.
+func settleDebitAttempt(attempt models.DebitAttempt, mandate models.DebitMandate, result utils.DebitResult) (string, error) {
+	tx := config.DB.Begin()
+
+	attempt.ProviderRef = result.ProviderRef
+	attempt.UpdatedAt = time.Now()
+
+	if err := tx.SavePoint("debit_posting").Error; err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	payment, err := postPayment(tx, paymentPosting{
+		LoanID:            attempt.LoanID,
+		PaymentAmount:     attempt.Amount,
+		PaymentChannel:    "AUTO_DEBIT",
+		PartnerCode:       "AUTO_DEBIT",
+		ExternalReference: fmt.Sprintf("DEBIT-%d", attempt.ID),
+	})
+	if err != nil {
+		var pe *postingError
+		if !errors.As(err, &pe) || pe.Status != http.StatusBadRequest {
+			tx.Rollback()
+			return "", err
+		}
+
+		// The money has been taken from the borrower's account, so keep it
+		// on their credit balance when the loan cannot accept it.
+		if err := tx.RollbackTo("debit_posting").Error; err != nil {
+			tx.Rollback()
+			return "", err
+		}
+		if _, err := adjustCreditBalance(tx, creditEntry{
+			UserID:          mandate.UserID,
+			LoanID:          &attempt.LoanID,
+			TransactionType: "UNMATCHED_FUNDS",
+			Amount:          attempt.Amount,
+			Note:            fmt.Sprintf("Auto-debit DEBIT-%d could not be posted: %s", attempt.ID, pe.Message),
+		}); err != nil {
+			tx.Rollback()
+			return "", err
+		}
+	} else {
+		attempt.PaymentID = &payment.ID
+	}
+
+	attempt.AttemptStatus = "SUCCEEDED"
+	if err := tx.Save(&attempt).Error; err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return attempt.AttemptStatus, tx.Commit().Error
+}
+
+// parkDebitAttempt moves an in-progress attempt to a status that waits for
+// reconciliation against the provider.
+func parkDebitAttempt(attemptID uint64, status, providerRef, reason string) error {
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+
+	return config.DB.Model(&models.DebitAttempt{}).
+		Where("id = ? AND attempt_status = ?", attemptID, "IN_PROGRESS").
+		Updates(map[string]interface{}{
+			"attempt_status": status,
+			"provider_ref":   providerRef,
+			"failure_reason": reason,
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+func firstAttemptTime(tx *gorm.DB, attempt models.DebitAttempt, fallback time.Time) time.Time {
+	var first models.DebitAttempt
+	if err := tx.
+		Where("installment_id = ? AND attempt_number = ?", attempt.InstallmentID, 1).
+		First(&first).Error; err != nil {
+		return fallback
+	}
+	return first.ScheduledAt
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errInsufficientCredit = errors.New("insufficient credit balance")
+
+type creditEntry struct {
+	UserID          uint64
+	LoanID          *uint64
+	PaymentID       *uint64
+	RefundID        *uint64
+	TransactionType string
+	Amount          float64
+	Note            string
+}
+
+// lockCreditBalance loads the borrower's balance row for update, creating it
+// on first use.
+func lockCreditBalance(tx *gorm.DB, userID uint64) (models.CreditBalance, error) {
+	seed := models.CreditBalance{
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoNothing: true,
+	}).Create(&seed).Error; err != nil {
+		return models.CreditBalance{}, err
+	}
+
+	var balance models.CreditBalance
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(&balance).Error
+	return balance, err
+}
+
+// adjustCreditBalance moves the balance by entry.Amount, which is positive for
+// money kept on behalf of the borrower and negative for money taken out.
+func adjustCreditBalance(tx *gorm.DB, entry creditEntry) (models.CreditTransaction, error) {
+	balance, err := lockCreditBalance(tx, entry.UserID)
+	if err != nil {
+		return models.CreditTransaction{}, err
+	}
+
+	newBalance := utils.RoundFloat(balance.Balance+entry.Amount, 2)
+	if newBalance < 0 {
+		return models.CreditTransaction{}, errInsufficientCredit
+	}
+
+	balance.Balance = newBalance
+	balance.UpdatedAt = time.Now()
+	if err := tx.Save(&balance).Error; err != nil {
+		return models.CreditTransaction{}, err
+	}
+
+	transaction := models.CreditTransaction{
+		UserID:          entry.UserID,
+		LoanID:          entry.LoanID,
+		PaymentID:       entry.PaymentID,
+		RefundID:        entry.RefundID,
+		TransactionType: entry.TransactionType,
+		Amount:          utils.RoundFloat(entry.Amount, 2),
+		BalanceAfter:    newBalance,
+		Note:            entry.Note,
+		CreatedAt:       time.Now(),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return models.CreditTransaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// applyCreditBalance uses the borrower's balance to pay the given loan, up to
+// the arrears plus the next installment due. It returns the payment made, or
+// nil when there was nothing to apply.
+func applyCreditBalance(tx *gorm.DB, userID, loanID uint64) (*models.Payment, error) {
+	balance, err := lockCreditBalance(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Balance <= 0 {
+		return nil, nil
+	}
+
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND paid_status = ?", loanID, "PENDING").
+		Order("sequence asc").
+		Find(&installments).Error; err != nil {
+		return nil, err
+	}
+	if len(installments) == 0 {
+		return nil, nil
+	}
+
+	nextDueDate := installments[0].DueDate
+	for _, inst := range installments {
+		if inst.DueDate.After(time.Now()) {
+			nextDueDate = inst.DueDate
+			break
+		}
+	}
+
+	var dueAmount float64
+	for _, inst := range installments {
+		if inst.DueDate.After(nextDueDate) {
+			break
+		}
+		dueAmount += (inst.PrincipalAmount - inst.PaidAmountPrincipal) + (inst.InterestAmount - inst.PaidAmountInterest)
+	}
+
+	amount := utils.RoundFloat(dueAmount, 2)
+	if balance.Balance < amount {
+		amount = balance.Balance
+	}
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	if err := tx.SavePoint("credit_application").Error; err != nil {
+		return nil, err
+	}
+
+	payment, err := postPayment(tx, paymentPosting{
+		LoanID:         loanID,
+		PaymentAmount:  amount,
+		PaymentChannel: "CREDIT_BALANCE",
+	})
+	if err != nil {
+		var pe *postingError
+		if errors.As(err, &pe) && pe.Status == http.StatusBadRequest {
+			// The balance does not cover the overdue minimum yet; leave it
+			// in place for the next attempt.
+			return nil, tx.RollbackTo("credit_application").Error
+		}
+		return nil, err
+	}
+
+	if _, err := adjustCreditBalance(tx, creditEntry{
+		UserID:          userID,
+		LoanID:          &loanID,
+		PaymentID:       &payment.ID,
+		TransactionType: "APPLIED",
+		Amount:          -amount,
+		Note:            "Applied to installment",
+	}); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
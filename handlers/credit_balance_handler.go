@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+func GetMyCreditBalance(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	respondCreditBalance(c, principal.UserID)
+}
+
+func GetCreditBalance(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	respondCreditBalance(c, userID)
+}
+
+func respondCreditBalance(c *gin.Context, userID uint64) {
+	var balance models.CreditBalance
+	if err := config.DB.Where("user_id = ?", userID).First(&balance).Error; err != nil {
+		balance = models.CreditBalance{UserID: userID}
+	}
+
+	var transactions []models.CreditTransaction
+	if err := config.DB.
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Limit(100).
+		Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch credit transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Credit balance fetched successfully",
+		"balance":      balance,
+		"transactions": transactions,
+	})
+}
+
+func ApplyDueCreditBalances(c *gin.Context) {
+	applied, err := sweepCreditBalances(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply credit balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Credit balances applied successfully",
+		"payments": applied,
+	})
+}
+
+// sweepCreditBalances applies positive balances to every active loan that has
+// an installment due on or before asOf.
+func sweepCreditBalances(asOf time.Time) ([]models.Payment, error) {
+	var balances []models.CreditBalance
+	if err := config.DB.Where("balance > 0").Find(&balances).Error; err != nil {
+		return nil, err
+	}
+
+	var applied []models.Payment
+	for _, balance := range balances {
+		var loans []models.Loan
+		if err := config.DB.
+			Where("user_id = ? AND loan_status = ?", balance.UserID, "ACTIVE").
+			Where("id IN (?)", config.DB.Model(&models.Installment{}).
+				Select("loan_id").
+				Where("paid_status = ? AND due_date <= ?", "PENDING", asOf)).
+			Order("created_at asc").
+			Find(&loans).Error; err != nil {
+			return applied, err
+		}
+
+		for _, loan := range loans {
+			tx := config.DB.Begin()
+
+			payment, err := applyCreditBalance(tx, balance.UserID, loan.ID)
+			if err != nil {
+				tx.Rollback()
+				return applied, err
+			}
+
+			if err := tx.Commit().Error; err != nil {
+				return applied, err
+			}
+			if payment != nil {
+				applied = append(applied, *payment)
+			}
+		}
+	}
+
+	return applied, nil
+}
+
+func CreateCreditRefund(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		Amount        float64 `json:"amount" binding:"required,gt=0"`
+		BankName      string  `json:"bank_name" binding:"required"`
+		AccountNumber string  `json:"account_number" binding:"required"`
+		AccountName   string  `json:"account_name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	refund := models.CreditRefund{
+		UserID:        userID,
+		Amount:        input.Amount,
+		BankName:      input.BankName,
+		AccountNumber: input.AccountNumber,
+		AccountName:   input.AccountName,
+		RefundStatus:  "REQUESTED",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := tx.Create(&refund).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create refund"})
+		return
+	}
+
+	if _, err := adjustCreditBalance(tx, creditEntry{
+		UserID:          userID,
+		RefundID:        &refund.ID,
+		TransactionType: "REFUND",
+		Amount:          -input.Amount,
+		Note:            "Refund to " + input.BankName + " " + input.AccountNumber,
+	}); err != nil {
+		tx.Rollback()
+		if errors.Is(err, errInsufficientCredit) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Refund amount exceeds credit balance"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to debit credit balance"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Refund requested successfully",
+		"refund":  refund,
+	})
+}
+
+func CompleteCreditRefund(c *gin.Context) {
+	var input struct {
+		TransferRef string `json:"transfer_ref" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var refund models.CreditRefund
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Refund not found"})
+		return
+	}
+
+	if refund.RefundStatus != "REQUESTED" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refund is not awaiting transfer"})
+		return
+	}
+
+	now := time.Now()
+	refund.RefundStatus = "PAID"
+	refund.TransferRef = input.TransferRef
+	refund.CompletedAt = &now
+	refund.UpdatedAt = now
+
+	if err := tx.Save(&refund).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete refund"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund completed successfully",
+		"refund":  refund,
+	})
+}
+
+func CancelCreditRefund(c *gin.Context) {
+	tx := config.DB.Begin()
+
+	var refund models.CreditRefund
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Refund not found"})
+		return
+	}
+
+	if refund.RefundStatus != "REQUESTED" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refund is not awaiting transfer"})
+		return
+	}
+
+	refund.RefundStatus = "CANCELLED"
+	refund.UpdatedAt = time.Now()
+
+	if err := tx.Save(&refund).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel refund"})
+		return
+	}
+
+	if _, err := adjustCreditBalance(tx, creditEntry{
+		UserID:          refund.UserID,
+		RefundID:        &refund.ID,
+		TransactionType: "REFUND_REVERSAL",
+		Amount:          refund.Amount,
+		Note:            "Refund cancelled",
+	}); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore credit balance"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund cancelled successfully",
+		"refund":  refund,
+	})
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// creditApplication is what the borrower asked for. It is stored with the
+// decision so a referred application can be booked after review. A top-up
+// names the loan it refinances.
+type creditApplication struct {
+	LoanAmount  float64          `json:"loan_amount"`
+	LoanLength  int              `json:"loan_length"`
+	ProductCode string           `json:"product_code"`
+	Parties     []loanPartyInput `json:"parties"`
+	PromoCode   string           `json:"promo_code,omitempty"`
+	TopUpLoanID uint64           `json:"top_up_loan_id,omitempty"`
+}
+
+// activateCreditRules stores definition as the newest rule version and
+// retires the previous one. A definition identical to the active version is
+// left alone and reported as unchanged.
+func activateCreditRules(tx *gorm.DB, definition []byte, source string, createdBy *uint64) (models.CreditRuleVersion, bool, error) {
+	ruleSet, err := utils.ParseCreditRules(definition)
+	if err != nil {
+		return models.CreditRuleVersion{}, false, err
+	}
+
+	sum := sha256.Sum256(definition)
+	checksum := hex.EncodeToString(sum[:])
+
+	var active models.CreditRuleVersion
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("version_status = ?", "ACTIVE").First(&active).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.CreditRuleVersion{}, false, err
+	}
+	if err == nil && active.Checksum == checksum {
+		return active, false, nil
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.CreditRuleVersion{}).
+		Where("version_status = ?", "ACTIVE").
+		Updates(map[string]interface{}{"version_status": "RETIRED", "retired_at": now}).Error; err != nil {
+		return models.CreditRuleVersion{}, false, err
+	}
+
+	var latest int
+	if err := tx.Model(&models.CreditRuleVersion{}).
+		Select("COALESCE(MAX(version_number), 0)").
+		Scan(&latest).Error; err != nil {
+		return models.CreditRuleVersion{}, false, err
+	}
+
+	version := models.CreditRuleVersion{
+		VersionNumber: latest + 1,
+		RuleSetName:   ruleSet.Name,
+		Definition:    string(definition),
+		Checksum:      checksum,
+		VersionStatus: "ACTIVE",
+		Source:        source,
+		CreatedBy:     createdBy,
+		CreatedAt:     now,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return models.CreditRuleVersion{}, false, err
+	}
+
+	return version, true, nil
+}
+
+// LoadCreditRulesFile activates the rule set at path when it differs from
+// the active version. An empty path does nothing.
+func LoadCreditRulesFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	definition, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+	version, changed, err := activateCreditRules(tx, definition, "FILE", nil)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if changed {
+		log.Printf("Activated credit rules version %d from %s", version.VersionNumber, path)
+	}
+	return nil
+}
+
+// gatherCreditFacts collects everything the rules can test about the
+// borrower and the offered loan. Missing profile data is reported as zero
+// so rules on income or age treat it as failing rather than passing.
+func gatherCreditFacts(tx *gorm.DB, userID uint64, offer loanOffer, asOf time.Time) (utils.CreditFacts, error) {
+	facts := utils.CreditFacts{
+		"loan_amount": offer.LoanAmount,
+		"loan_length": float64(offer.LoanLength),
+	}
+
+	var installmentAmount float64
+	for _, line := range offer.Schedule {
+		installmentAmount = math.Max(installmentAmount, line.InstallmentAmount)
+	}
+	facts["installment_amount"] = utils.RoundFloat(installmentAmount, 2)
+
+	var profile models.BorrowerProfile
+	err := tx.Where("user_id = ?", userID).First(&profile).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	facts["monthly_income"] = profile.MonthlyIncome
+	facts["kyc_verified"] = 0
+	if profile.KYCStatus == "VERIFIED" {
+		facts["kyc_verified"] = 1
+	}
+
+	facts["age"] = 0
+	if !profile.DateOfBirth.IsZero() {
+		age := asOf.Year() - profile.DateOfBirth.Year()
+		if asOf.Month() < profile.DateOfBirth.Month() ||
+			(asOf.Month() == profile.DateOfBirth.Month() && asOf.Day() < profile.DateOfBirth.Day()) {
+			age--
+		}
+		facts["age"] = float64(age)
+	}
+
+	// Installments are weekly, so 52/12 of one is the monthly burden.
+	facts["amount_to_income"] = math.Inf(1)
+	facts["installment_to_income"] = math.Inf(1)
+	if profile.MonthlyIncome > 0 {
+		facts["amount_to_income"] = utils.RoundFloat(offer.LoanAmount/profile.MonthlyIncome, 4)
+		facts["installment_to_income"] = utils.RoundFloat(installmentAmount*52/12/profile.MonthlyIncome, 4)
+	}
+
+	exposure, err := borrowerExposure(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	facts["existing_exposure"] = exposure
+
+	var activeLoans, writtenOffLoans int64
+	if err := tx.Model(&models.Loan{}).Where("user_id = ? AND loan_status = ?", userID, "ACTIVE").Count(&activeLoans).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.Loan{}).Where("user_id = ? AND loan_status = ?", userID, "WRITTEN_OFF").Count(&writtenOffLoans).Error; err != nil {
+		return nil, err
+	}
+	facts["active_loans"] = float64(activeLoans)
+	facts["written_off_loans"] = float64(writtenOffLoans)
+
+	late, maxDPD, currentDPD, err := repaymentHistory(tx, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	facts["late_installments"] = float64(late)
+	facts["max_days_past_due"] = float64(maxDPD)
+	facts["current_days_past_due"] = float64(currentDPD)
+
+	return facts, nil
+}
+
+// repaymentHistory measures how late the borrower has paid. A paid
+// installment counts up to the day it was settled and a pending one up to
+// asOf; late installments are those that reached 14 days past due.
+func repaymentHistory(tx *gorm.DB, userID uint64, asOf time.Time) (late, maxDPD, currentDPD int, err error) {
+	var installments []models.Installment
+	if err := tx.
+		Where("user_id = ? AND paid_status IN ?", userID, []string{"PAID", "PENDING"}).
+		Where("due_date < ?", asOf).
+		Find(&installments).Error; err != nil {
+		return 0, 0, 0, err
+	}
+
+	calendar, err := loadBusinessCalendar(tx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	adjustments := make(map[uint64]string)
+	for _, inst := range installments {
+		adjustment, ok := adjustments[inst.LoanID]
+		if !ok {
+			var loan models.Loan
+			if err := tx.First(&loan, inst.LoanID).Error; err != nil {
+				return 0, 0, 0, err
+			}
+			if adjustment, err = loanDateAdjustment(tx, loan); err != nil {
+				return 0, 0, 0, err
+			}
+			adjustments[inst.LoanID] = adjustment
+		}
+
+		end := asOf
+		if inst.PaidStatus == "PAID" {
+			end = inst.UpdatedAt
+		}
+
+		days := int(end.Sub(effectiveDueDate(calendar, inst.DueDate, adjustment)).Hours() / 24)
+		if days <= 0 {
+			continue
+		}
+		if days >= 14 {
+			late++
+		}
+		if days > maxDPD {
+			maxDPD = days
+		}
+		if inst.PaidStatus == "PENDING" && days > currentDPD {
+			currentDPD = days
+		}
+	}
+
+	return late, maxDPD, currentDPD, nil
+}
+
+// decideApplication evaluates the application against the active rule
+// version and records the decision. With no rules configured every
+// application is approved, as before the engine existed.
+func decideApplication(tx *gorm.DB, userID uint64, offer loanOffer, application creditApplication) (models.CreditDecision, error) {
+	now := time.Now()
+
+	facts, err := gatherCreditFacts(tx, userID, offer, now)
+	if err != nil {
+		return models.CreditDecision{}, err
+	}
+
+	evaluation := utils.CreditEvaluation{
+		Decision: utils.CreditDecisionApprove,
+		Reasons:  []string{"No active credit rules"},
+		Fired:    []string{},
+	}
+
+	decision := models.CreditDecision{UserID: userID, ReviewStatus: "NOT_REQUIRED", CreatedAt: now, UpdatedAt: now}
+
+	var version models.CreditRuleVersion
+	err = tx.Where("version_status = ?", "ACTIVE").First(&version).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.CreditDecision{}, err
+	}
+	if err == nil {
+		ruleSet, err := utils.ParseCreditRules([]byte(version.Definition))
+		if err != nil {
+			return models.CreditDecision{}, fmt.Errorf("active credit rules version %d: %w", version.VersionNumber, err)
+		}
+		evaluation = utils.EvaluateCreditRules(ruleSet, facts)
+		decision.RuleVersionID = &version.ID
+	}
+
+	decision.Decision = evaluation.Decision
+	decision.RiskGrade = evaluation.Grade
+	if evaluation.Decision == utils.CreditDecisionManualReview {
+		decision.ReviewStatus = "PENDING"
+	}
+
+	// JSON has no infinity, so ratios against a missing income are stored
+	// as null.
+	storedFacts := make(map[string]*float64, len(facts))
+	for field, value := range facts {
+		if math.IsInf(value, 0) {
+			storedFacts[field] = nil
+			continue
+		}
+		value := value
+		storedFacts[field] = &value
+	}
+
+	var encodeErr error
+	encode := func(value interface{}) string {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encodeErr = err
+		}
+		return string(encoded)
+	}
+	decision.Reasons = encode(evaluation.Reasons)
+	decision.FiredRules = encode(evaluation.Fired)
+	decision.Facts = encode(storedFacts)
+	decision.Application = encode(application)
+	if encodeErr != nil {
+		return models.CreditDecision{}, encodeErr
+	}
+
+	if err := tx.Create(&decision).Error; err != nil {
+		return models.CreditDecision{}, err
+	}
+
+	return decision, nil
+}
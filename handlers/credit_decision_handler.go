@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+const maxCreditRulesSize = 1 << 20
+
+func GetCreditRuleVersions(c *gin.Context) {
+	var versions []models.CreditRuleVersion
+	if err := config.DB.Order("version_number desc").Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch credit rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Credit rules fetched successfully",
+		"versions": versions,
+	})
+}
+
+// CreateCreditRuleVersion takes a YAML or JSON rule set as the raw request
+// body and makes it the active version.
+func CreateCreditRuleVersion(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	definition, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCreditRulesSize+1))
+	if err != nil || len(definition) > maxCreditRulesSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read rule definition"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	version, changed, err := activateCreditRules(tx, definition, "API", &principal.UserID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx.Commit()
+
+	if !changed {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Credit rules unchanged",
+			"version": version,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Credit rules activated successfully",
+		"version": version,
+	})
+}
+
+func GetCreditDecisions(c *gin.Context) {
+	query := config.DB.Order("created_at desc").Limit(100)
+	if status := c.Query("review_status"); status != "" {
+		query = query.Where("review_status = ?", status)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var decisions []models.CreditDecision
+	if err := query.Find(&decisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch credit decisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Credit decisions fetched successfully",
+		"decisions": decisions,
+	})
+}
+
+func GetCreditDecision(c *gin.Context) {
+	var decision models.CreditDecision
+	if err := config.DB.First(&decision, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Credit decision not found"})
+		return
+	}
+
+	var version *models.CreditRuleVersion
+	if decision.RuleVersionID != nil {
+		version = &models.CreditRuleVersion{}
+		if err := config.DB.First(version, *decision.RuleVersionID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rule version"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Credit decision fetched successfully",
+		"decision":     decision,
+		"rule_version": version,
+	})
+}
+
+// ReviewCreditDecision settles an application referred for manual review.
+// Approval books the loan from the stored application, priced as of today
+// and subject to the usual eligibility checks.
+func ReviewCreditDecision(c *gin.Context) {
+	var input struct {
+		Status    string `json:"status" binding:"required,oneof=APPROVED REJECTED"`
+		Note      string `json:"note"`
+		RiskGrade string `json:"risk_grade"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var decision models.CreditDecision
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&decision, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Credit decision not found"})
+		return
+	}
+
+	if decision.ReviewStatus != "PENDING" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Credit decision is not awaiting review"})
+		return
+	}
+
+	var loan *models.Loan
+	if input.Status == "APPROVED" {
+		var application creditApplication
+		if err := json.Unmarshal([]byte(decision.Application), &application); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read stored application"})
+			return
+		}
+
+		// An underwriter may grade the application while approving it.
+		riskGrade := strings.ToUpper(input.RiskGrade)
+		if riskGrade != "" && !utils.ValidRiskGrade(riskGrade) {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk grade"})
+			return
+		}
+		if riskGrade == "" {
+			resolved, err := resolveRiskGrade(tx, decision.UserID, decision.RiskGrade)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve risk grade"})
+				return
+			}
+			riskGrade = resolved
+		}
+		decision.RiskGrade = riskGrade
+
+		if application.TopUpLoanID != 0 {
+			// A referred top-up is re-checked and re-quoted as of approval,
+			// since the old loan's payoff has moved on since it was referred.
+			var previous models.Loan
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, application.TopUpLoanID).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+				return
+			}
+
+			if riskGrade == "" {
+				riskGrade = previous.RiskGrade
+				decision.RiskGrade = riskGrade
+			}
+
+			now := time.Now()
+			offer, quote, err := prepareTopUp(tx, previous, application.ProductCode, application.LoanAmount, application.LoanLength, riskGrade, now)
+			if err != nil {
+				tx.Rollback()
+				respondOfferError(c, err)
+				return
+			}
+
+			newLoan, _, err := bookTopUp(tx, &previous, offer, quote, now)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to top up loan"})
+				return
+			}
+			loan = &newLoan
+		} else {
+			offer, err := prepareLoanOffer(tx, application.ProductCode, application.LoanAmount, application.LoanLength, time.Now(), riskGrade)
+			if err != nil {
+				tx.Rollback()
+				respondOfferError(c, err)
+				return
+			}
+
+			if err := checkLoanEligibility(tx, decision.UserID, offer, 0); err != nil {
+				tx.Rollback()
+				respondOfferError(c, err)
+				return
+			}
+
+			if application.PromoCode != "" {
+				if err := applyPromo(tx, &offer, application.PromoCode, decision.UserID, true); err != nil {
+					tx.Rollback()
+					respondOfferError(c, err)
+					return
+				}
+			}
+
+			loan = &models.Loan{UserID: decision.UserID}
+			if err := createLoanFromOffer(tx, loan, offer, application.Parties); err != nil {
+				tx.Rollback()
+				if _, ok := err.(*offerError); ok {
+					respondOfferError(c, err)
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
+				return
+			}
+		}
+		decision.LoanID = &loan.ID
+	}
+
+	now := time.Now()
+	decision.ReviewStatus = input.Status
+	decision.ReviewNote = input.Note
+	decision.ReviewedBy = &principal.UserID
+	decision.ReviewedAt = &now
+	decision.UpdatedAt = now
+
+	if err := tx.Save(&decision).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review credit decision"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Credit decision reviewed successfully",
+		"decision": decision,
+		"loan":     loan,
+	})
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// borrowerExposure is the principal still unpaid across all of the
+// borrower's active loans and the open drawdowns on their credit lines.
+func borrowerExposure(tx *gorm.DB, userID uint64) (float64, error) {
+	var loans float64
+	if err := tx.Model(&models.Installment{}).
+		Where("user_id = ? AND paid_status = ?", userID, "PENDING").
+		Select("COALESCE(SUM(principal_amount - paid_amount_principal), 0)").
+		Scan(&loans).Error; err != nil {
+		return 0, err
+	}
+
+	var drawdowns float64
+	if err := tx.Model(&models.CreditLine{}).
+		Where("user_id = ? AND line_status != ?", userID, "CLOSED").
+		Select("COALESCE(SUM(outstanding_principal), 0)").
+		Scan(&drawdowns).Error; err != nil {
+		return 0, err
+	}
+
+	return utils.RoundFloat(loans+drawdowns, 2), nil
+}
+
+// checkLoanEligibility decides whether the borrower may take the offered
+// loan. A borrower with a credit limit may hold several active loans while
+// their combined unpaid principal stays within it; a borrower without one
+// keeps the original rule of one open loan at a time. When
+// config.RequireVerifiedKYC is set the borrower's KYC must be verified.
+// A non-zero replacingLoanID names a loan the new one will settle, which is
+// left out of every check.
+func checkLoanEligibility(tx *gorm.DB, userID uint64, offer loanOffer, replacingLoanID uint64) error {
+	if config.RequireVerifiedKYC {
+		var profile models.BorrowerProfile
+		if err := tx.Where("user_id = ?", userID).First(&profile).Error; err != nil || profile.KYCStatus != "VERIFIED" {
+			return &offerError{http.StatusBadRequest, "Borrower KYC must be verified before taking a loan"}
+		}
+	}
+
+	var writtenOff int64
+	if err := tx.Model(&models.Loan{}).
+		Where("user_id = ? AND loan_status = ?", userID, "WRITTEN_OFF").
+		Count(&writtenOff).Error; err != nil {
+		return err
+	}
+	if writtenOff > 0 {
+		return &offerError{http.StatusBadRequest, "User has a written-off loan, cannot create new loan"}
+	}
+
+	if offer.Product.OneActiveLoan {
+		var sameProduct int64
+		if err := tx.Model(&models.Loan{}).
+			Where("user_id = ? AND product_id = ? AND loan_status = ?", userID, offer.Product.ID, "ACTIVE").
+			Where("id <> ?", replacingLoanID).
+			Count(&sameProduct).Error; err != nil {
+			return err
+		}
+		if sameProduct > 0 {
+			return &offerError{http.StatusBadRequest, "User already has an active loan for this product"}
+		}
+	}
+
+	hasLimit, err := checkCreditLimit(tx, userID, offer.NTFTotal, replacingLoanID)
+	if err != nil || hasLimit {
+		return err
+	}
+
+	var existingLoan models.Loan
+	if err := tx.Where("user_id = ? AND loan_status != ? AND id <> ?", userID, "CLOSED", replacingLoanID).First(&existingLoan).Error; err == nil {
+		return &offerError{http.StatusBadRequest, "User has an active loan, cannot create new loan"}
+	}
+	return nil
+}
+
+// checkCreditLimit refuses new borrowing of amount that would take the
+// borrower past their credit limit, and reports whether they have one. The
+// limit row is locked so concurrent borrowing cannot both fit under it. A
+// non-zero replacingLoanID names a loan the borrowing will settle, whose
+// principal is not counted.
+func checkCreditLimit(tx *gorm.DB, userID uint64, amount float64, replacingLoanID uint64) (bool, error) {
+	var limit models.CreditLimit
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&limit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	exposure, err := borrowerExposure(tx, userID)
+	if err != nil {
+		return true, err
+	}
+
+	if replacingLoanID != 0 {
+		var replaced float64
+		if err := tx.Model(&models.Installment{}).
+			Where("loan_id = ? AND paid_status = ?", replacingLoanID, "PENDING").
+			Select("COALESCE(SUM(principal_amount - paid_amount_principal), 0)").
+			Scan(&replaced).Error; err != nil {
+			return true, err
+		}
+		exposure = utils.RoundFloat(exposure-replaced, 2)
+	}
+
+	if available := limit.LimitAmount - exposure; amount > available {
+		return true, &offerError{
+			http.StatusBadRequest,
+			fmt.Sprintf("Borrowing exceeds available credit limit: %.2f", utils.Max(available, 0)),
+		}
+	}
+
+	return true, nil
+}
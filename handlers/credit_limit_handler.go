@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetMyCreditLimit(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	respondCreditLimit(c, principal.UserID)
+}
+
+func GetCreditLimit(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	respondCreditLimit(c, userID)
+}
+
+func respondCreditLimit(c *gin.Context, userID uint64) {
+	var limit models.CreditLimit
+	if err := config.DB.Where("user_id = ?", userID).First(&limit).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Credit limit not set"})
+		return
+	}
+
+	exposure, err := borrowerExposure(config.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute exposure"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Credit limit fetched successfully",
+		"credit_limit":     limit,
+		"outstanding":      exposure,
+		"available_credit": utils.RoundFloat(utils.Max(limit.LimitAmount-exposure, 0), 2),
+	})
+}
+
+func SetCreditLimit(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		LimitAmount float64 `json:"limit_amount" binding:"gte=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var limit models.CreditLimit
+	if err := config.DB.Where("user_id = ?", userID).First(&limit).Error; err != nil {
+		limit = models.CreditLimit{UserID: userID, CreatedAt: time.Now()}
+	}
+
+	limit.LimitAmount = input.LimitAmount
+	limit.UpdatedBy = principal.UserID
+	limit.UpdatedAt = time.Now()
+
+	if err := config.DB.Save(&limit).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save credit limit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Credit limit saved successfully",
+		"credit_limit": limit,
+	})
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const statementPaymentDays = 14
+
+type creditLineEntry struct {
+	DrawdownID      *uint64
+	TransactionType string
+	PrincipalAmount float64
+	InterestAmount  float64
+	Reference       string
+}
+
+func lockCreditLine(tx *gorm.DB, lineID uint64) (models.CreditLine, error) {
+	var line models.CreditLine
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&line, lineID).Error
+	return line, err
+}
+
+// postCreditLineEntry moves the line's revolving principal and billed
+// interest by the signed amounts in entry and records it in the ledger. The
+// line must already be locked by the caller.
+func postCreditLineEntry(tx *gorm.DB, line *models.CreditLine, entry creditLineEntry) error {
+	line.OutstandingPrincipal = utils.RoundFloat(line.OutstandingPrincipal+entry.PrincipalAmount, 2)
+	line.InterestDue = utils.RoundFloat(line.InterestDue+entry.InterestAmount, 2)
+	line.UpdatedAt = time.Now()
+	if err := tx.Save(line).Error; err != nil {
+		return err
+	}
+
+	transaction := models.CreditLineTransaction{
+		CreditLineID:    line.ID,
+		DrawdownID:      entry.DrawdownID,
+		TransactionType: entry.TransactionType,
+		PrincipalAmount: utils.RoundFloat(entry.PrincipalAmount, 2),
+		InterestAmount:  utils.RoundFloat(entry.InterestAmount, 2),
+		PrincipalAfter:  line.OutstandingPrincipal,
+		InterestAfter:   line.InterestDue,
+		Reference:       entry.Reference,
+		CreatedAt:       time.Now(),
+	}
+	return tx.Create(&transaction).Error
+}
+
+// availableCredit is the line limit less revolving principal and the unpaid
+// principal of loans converted from the line's drawdowns, so it rises again
+// as either is repaid.
+func availableCredit(tx *gorm.DB, line models.CreditLine) (float64, error) {
+	var convertedPrincipal float64
+	if err := tx.Model(&models.Installment{}).
+		Where("paid_status = ?", "PENDING").
+		Where("loan_id IN (?)", tx.Model(&models.Loan{}).Select("id").Where("credit_line_id = ?", line.ID)).
+		Select("COALESCE(SUM(principal_amount - paid_amount_principal), 0)").
+		Scan(&convertedPrincipal).Error; err != nil {
+		return 0, err
+	}
+
+	return utils.RoundFloat(line.LimitAmount-line.OutstandingPrincipal-convertedPrincipal, 2), nil
+}
+
+// nextStatementDate returns the first date after from that falls on the
+// line's statement day.
+func nextStatementDate(from time.Time, statementDay int) time.Time {
+	next := time.Date(from.Year(), from.Month(), statementDay, 0, 0, 0, 0, from.Location())
+	if !next.After(from) {
+		next = next.AddDate(0, 1, 0)
+	}
+	return next
+}
+
+// runStatementCycle issues a statement for every open line whose statement
+// date has passed. Interest is charged on the average daily revolving
+// principal over the cycle, using the ledger to find the balance on each day.
+func runStatementCycle(asOf time.Time) (int, error) {
+	var lines []models.CreditLine
+	if err := config.DB.
+		Where("line_status != ? AND next_statement_date <= ?", "CLOSED", asOf).
+		Find(&lines).Error; err != nil {
+		return 0, err
+	}
+
+	issued := 0
+	for _, candidate := range lines {
+		for {
+			tx := config.DB.Begin()
+
+			statement, err := issueStatement(tx, candidate.ID, asOf)
+			if err != nil {
+				tx.Rollback()
+				return issued, err
+			}
+			if err := tx.Commit().Error; err != nil {
+				return issued, err
+			}
+			if statement == nil {
+				break
+			}
+			issued++
+		}
+	}
+
+	return issued, nil
+}
+
+func issueStatement(tx *gorm.DB, lineID uint64, asOf time.Time) (*models.CreditLineStatement, error) {
+	line, err := lockCreditLine(tx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line.NextStatementDate.After(asOf) {
+		return nil, nil
+	}
+
+	periodStart := line.CreatedAt
+	var openingPrincipal, openingBalance float64
+	var previous models.CreditLineStatement
+	if err := tx.Where("credit_line_id = ?", line.ID).Order("period_end desc").First(&previous).Error; err == nil {
+		periodStart = previous.PeriodEnd
+		openingPrincipal = previous.ClosingPrincipal
+		openingBalance = previous.ClosingBalance
+	}
+	periodEnd := line.NextStatementDate
+
+	var transactions []models.CreditLineTransaction
+	if err := tx.
+		Where("credit_line_id = ? AND created_at >= ? AND created_at < ?", line.ID, periodStart, periodEnd).
+		Order("created_at asc, id asc").
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	statement := models.CreditLineStatement{
+		CreditLineID:   line.ID,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		OpeningBalance: openingBalance,
+		DueDate:        periodEnd.AddDate(0, 0, statementPaymentDays),
+		CreatedAt:      time.Now(),
+	}
+
+	principal := openingPrincipal
+	last := periodStart
+	var principalDays float64
+	for _, transaction := range transactions {
+		principalDays += principal * transaction.CreatedAt.Sub(last).Hours() / 24
+		principal = transaction.PrincipalAfter
+		last = transaction.CreatedAt
+
+		switch transaction.TransactionType {
+		case "DRAWDOWN":
+			statement.TotalDrawdowns += transaction.PrincipalAmount
+		case "PAYMENT":
+			statement.TotalPayments -= transaction.PrincipalAmount + transaction.InterestAmount
+		case "CONVERSION":
+			statement.TotalConversions -= transaction.PrincipalAmount
+		}
+	}
+	principalDays += principal * periodEnd.Sub(last).Hours() / 24
+
+	interest := utils.RoundFloat(principalDays/365*line.InterestRate/100, 2)
+	if interest > 0 {
+		if err := postCreditLineEntry(tx, &line, creditLineEntry{
+			TransactionType: "INTEREST",
+			InterestAmount:  interest,
+			Reference:       "Statement " + periodEnd.Format("2006-01-02"),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	statement.InterestCharged = interest
+	statement.TotalDrawdowns = utils.RoundFloat(statement.TotalDrawdowns, 2)
+	statement.TotalPayments = utils.RoundFloat(statement.TotalPayments, 2)
+	statement.TotalConversions = utils.RoundFloat(statement.TotalConversions, 2)
+	statement.ClosingPrincipal = line.OutstandingPrincipal
+	statement.ClosingInterest = line.InterestDue
+	statement.ClosingBalance = utils.RoundFloat(line.OutstandingPrincipal+line.InterestDue, 2)
+	statement.MinimumDue = utils.RoundFloat(
+		line.InterestDue+line.OutstandingPrincipal*line.MinimumPaymentRate/100, 2)
+
+	if err := tx.Create(&statement).Error; err != nil {
+		return nil, err
+	}
+
+	line.NextStatementDate = line.NextStatementDate.AddDate(0, 1, 0)
+	line.UpdatedAt = time.Now()
+	if err := tx.Save(&line).Error; err != nil {
+		return nil, err
+	}
+
+	return &statement, nil
+}
@@ -0,0 +1,507 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreateCreditLine(c *gin.Context) {
+	var input struct {
+		UserID             uint64  `json:"user_id" binding:"required"`
+		LimitAmount        float64 `json:"limit_amount" binding:"required,gt=0"`
+		InterestRate       float64 `json:"interest_rate" binding:"gte=0"`
+		MinimumPaymentRate float64 `json:"minimum_payment_rate" binding:"gte=0,lte=100"`
+		StatementDay       int     `json:"statement_day" binding:"required,min=1,max=28"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	minimumPaymentRate := input.MinimumPaymentRate
+	if minimumPaymentRate == 0 {
+		minimumPaymentRate = 10
+	}
+
+	now := time.Now()
+	line := models.CreditLine{
+		UserID:             user.ID,
+		LineCode:           utils.GenerateCreditLineCode(),
+		LimitAmount:        input.LimitAmount,
+		InterestRate:       input.InterestRate,
+		MinimumPaymentRate: minimumPaymentRate,
+		StatementDay:       input.StatementDay,
+		NextStatementDate:  nextStatementDate(now, input.StatementDay),
+		LineStatus:         "ACTIVE",
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	if err := config.DB.Create(&line).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create credit line"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Credit line created successfully",
+		"credit_line": line,
+	})
+}
+
+func GetMyCreditLines(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var lines []models.CreditLine
+	if err := config.DB.Where("user_id = ?", principal.UserID).Order("created_at asc").Find(&lines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch credit lines"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(lines))
+	for _, line := range lines {
+		available, err := availableCredit(config.DB, line)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute available credit"})
+			return
+		}
+		result = append(result, gin.H{"credit_line": line, "available_credit": available})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Credit lines fetched successfully",
+		"credit_lines": result,
+	})
+}
+
+func GetCreditLine(c *gin.Context) {
+	line, ok := findCreditLine(c, config.DB, false)
+	if !ok {
+		return
+	}
+
+	available, err := availableCredit(config.DB, line)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute available credit"})
+		return
+	}
+
+	var drawdowns []models.Drawdown
+	if err := config.DB.Where("credit_line_id = ?", line.ID).Order("created_at asc").Find(&drawdowns).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drawdowns"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Credit line fetched successfully",
+		"credit_line":      line,
+		"available_credit": available,
+		"drawdowns":        drawdowns,
+	})
+}
+
+func CreateDrawdown(c *gin.Context) {
+	var input struct {
+		Amount      float64 `json:"amount" binding:"required,gt=0"`
+		Description string  `json:"description"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	line, ok := findCreditLine(c, tx, true)
+	if !ok {
+		tx.Rollback()
+		return
+	}
+
+	if line.LineStatus != "ACTIVE" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Credit line is not active"})
+		return
+	}
+
+	available, err := availableCredit(tx, line)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute available credit"})
+		return
+	}
+
+	if input.Amount > available {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Drawdown exceeds available credit: %.2f", utils.Max(available, 0))})
+		return
+	}
+
+	if _, err := checkCreditLimit(tx, line.UserID, input.Amount, 0); err != nil {
+		tx.Rollback()
+		if _, ok := err.(*offerError); ok {
+			respondOfferError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check credit limit"})
+		return
+	}
+
+	drawdown := models.Drawdown{
+		CreditLineID:   line.ID,
+		UserID:         line.UserID,
+		Amount:         input.Amount,
+		Description:    input.Description,
+		DrawdownStatus: "OPEN",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := tx.Create(&drawdown).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create drawdown"})
+		return
+	}
+
+	if err := postCreditLineEntry(tx, &line, creditLineEntry{
+		DrawdownID:      &drawdown.ID,
+		TransactionType: "DRAWDOWN",
+		PrincipalAmount: input.Amount,
+		Reference:       input.Description,
+	}); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update credit line"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":          "Drawdown created successfully",
+		"drawdown":         drawdown,
+		"available_credit": utils.RoundFloat(available-input.Amount, 2),
+	})
+}
+
+// PayCreditLine repays the revolving balance: billed interest first, then
+// open drawdowns oldest first. Anything left over goes to the borrower's
+// credit balance, as with loan payments.
+func PayCreditLine(c *gin.Context) {
+	var input struct {
+		Amount    float64 `json:"amount" binding:"required,gt=0"`
+		Reference string  `json:"reference"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	line, ok := findCreditLine(c, tx, true)
+	if !ok {
+		tx.Rollback()
+		return
+	}
+
+	remaining := input.Amount
+	interestPaid := utils.RoundFloat(utils.Min(remaining, line.InterestDue), 2)
+	remaining = utils.RoundFloat(remaining-interestPaid, 2)
+
+	var drawdowns []models.Drawdown
+	if err := tx.
+		Where("credit_line_id = ? AND drawdown_status = ?", line.ID, "OPEN").
+		Order("created_at asc").
+		Find(&drawdowns).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load drawdowns"})
+		return
+	}
+
+	var principalPaid float64
+	for i := 0; i < len(drawdowns) && remaining > 0; i++ {
+		drawdown := &drawdowns[i]
+		pay := utils.RoundFloat(utils.Min(remaining, drawdown.Amount-drawdown.PrincipalPaid), 2)
+
+		drawdown.PrincipalPaid = utils.RoundFloat(drawdown.PrincipalPaid+pay, 2)
+		if drawdown.PrincipalPaid >= drawdown.Amount {
+			drawdown.DrawdownStatus = "PAID"
+		}
+		drawdown.UpdatedAt = time.Now()
+
+		if err := tx.Save(drawdown).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update drawdown"})
+			return
+		}
+
+		principalPaid += pay
+		remaining = utils.RoundFloat(remaining-pay, 2)
+	}
+
+	if interestPaid == 0 && principalPaid == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Credit line has no balance to pay"})
+		return
+	}
+
+	if err := postCreditLineEntry(tx, &line, creditLineEntry{
+		TransactionType: "PAYMENT",
+		PrincipalAmount: -principalPaid,
+		InterestAmount:  -interestPaid,
+		Reference:       input.Reference,
+	}); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update credit line"})
+		return
+	}
+
+	if remaining > 0 {
+		if _, err := adjustCreditBalance(tx, creditEntry{
+			UserID:          line.UserID,
+			TransactionType: "OVERPAYMENT",
+			Amount:          remaining,
+			Note:            "Excess over balance of credit line " + line.LineCode,
+		}); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to credit excess payment"})
+			return
+		}
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Payment processed successfully",
+		"interest_paid":  interestPaid,
+		"principal_paid": utils.RoundFloat(principalPaid, 2),
+		"excess_amount":  remaining,
+		"credit_line":    line,
+	})
+}
+
+// ConvertDrawdown turns the unpaid part of an open drawdown into a term loan
+// built by the regular installment generator. The loan stays linked to the
+// line and keeps counting against its limit until it is repaid.
+func ConvertDrawdown(c *gin.Context) {
+	var input struct {
+		LoanLength  int    `json:"loan_length" binding:"required"`
+		ProductCode string `json:"product_code"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	line, ok := findCreditLine(c, tx, true)
+	if !ok {
+		tx.Rollback()
+		return
+	}
+
+	var drawdown models.Drawdown
+	if err := tx.Where("id = ? AND credit_line_id = ?", c.Param("drawdown_id"), line.ID).First(&drawdown).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Drawdown not found"})
+		return
+	}
+
+	if drawdown.DrawdownStatus != "OPEN" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open drawdowns can be converted"})
+		return
+	}
+
+	remainingPrincipal := utils.RoundFloat(drawdown.Amount-drawdown.PrincipalPaid, 2)
+
+	riskGrade, err := resolveRiskGrade(tx, line.UserID, "")
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve risk grade"})
+		return
+	}
+
+	offer, err := prepareLoanOffer(tx, input.ProductCode, remainingPrincipal, input.LoanLength, time.Now(), riskGrade)
+	if err != nil {
+		tx.Rollback()
+		respondOfferError(c, err)
+		return
+	}
+
+	available, err := availableCredit(tx, line)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute available credit"})
+		return
+	}
+
+	if fee := offer.NTFTotal - remainingPrincipal; fee > available {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Conversion fee exceeds available credit"})
+		return
+	}
+
+	// The drawdown's principal already counts in the borrower's exposure
+	// through the line, so only the conversion fee is new borrowing.
+	eligibility := offer
+	eligibility.NTFTotal = offer.NTFTotal - remainingPrincipal
+	if err := checkLoanEligibility(tx, line.UserID, eligibility, 0); err != nil {
+		tx.Rollback()
+		respondOfferError(c, err)
+		return
+	}
+
+	// The drawdown is already paid out, so a rejection leaves it on the line
+	// and a referral is kept for the record without holding up the
+	// conversion.
+	decision, err := decideApplication(tx, line.UserID, offer, creditApplication{
+		LoanAmount:  remainingPrincipal,
+		LoanLength:  input.LoanLength,
+		ProductCode: input.ProductCode,
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate loan application"})
+		return
+	}
+
+	if decision.Decision == utils.CreditDecisionReject {
+		tx.Commit()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Drawdown conversion rejected", "decision": decision})
+		return
+	}
+
+	loan := models.Loan{UserID: line.UserID, CreditLineID: &line.ID}
+	if err := createLoanFromOffer(tx, &loan, offer, nil); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
+		return
+	}
+
+	decision.LoanID = &loan.ID
+	decision.ReviewStatus = "NOT_REQUIRED"
+	if err := tx.Save(&decision).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record credit decision"})
+		return
+	}
+
+	drawdown.DrawdownStatus = "CONVERTED"
+	drawdown.LoanID = &loan.ID
+	drawdown.UpdatedAt = time.Now()
+
+	if err := tx.Save(&drawdown).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update drawdown"})
+		return
+	}
+
+	if err := postCreditLineEntry(tx, &line, creditLineEntry{
+		DrawdownID:      &drawdown.ID,
+		TransactionType: "CONVERSION",
+		PrincipalAmount: -remainingPrincipal,
+		Reference:       loan.LoanCode,
+	}); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update credit line"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Drawdown converted successfully",
+		"drawdown":   drawdown,
+		"loan":       loan,
+		"disclosure": offer.Disclosure,
+		"decision":   decision,
+	})
+}
+
+func GetCreditLineStatements(c *gin.Context) {
+	line, ok := findCreditLine(c, config.DB, false)
+	if !ok {
+		return
+	}
+
+	var statements []models.CreditLineStatement
+	if err := config.DB.
+		Where("credit_line_id = ?", line.ID).
+		Order("period_end desc").
+		Find(&statements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Statements fetched successfully",
+		"statements": statements,
+	})
+}
+
+func RunCreditLineStatements(c *gin.Context) {
+	issued, err := runStatementCycle(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run statement cycle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statement cycle completed",
+		"issued":  issued,
+	})
+}
+
+// findCreditLine loads the line named in the request. Users may only reach
+// their own lines; API clients act for any borrower. With lock set the line
+// row is locked for the rest of db's transaction.
+func findCreditLine(c *gin.Context, db *gorm.DB, lock bool) (models.CreditLine, bool) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return models.CreditLine{}, false
+	}
+
+	lineID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credit line ID"})
+		return models.CreditLine{}, false
+	}
+
+	var line models.CreditLine
+	if lock {
+		line, err = lockCreditLine(db, lineID)
+	} else {
+		err = db.First(&line, lineID).Error
+	}
+	if err != nil || (principal.IsUser() && line.UserID != principal.UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Credit line not found"})
+		return models.CreditLine{}, false
+	}
+
+	return line, true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+func GetHolidays(c *gin.Context) {
+	query := config.DB.Order("holiday_date asc")
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("holiday_date >= ? AND holiday_date < ?", from, from.AddDate(1, 0, 0))
+	}
+
+	var holidays []models.Holiday
+	if err := query.Find(&holidays).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch holidays"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Holidays fetched successfully",
+		"holidays": holidays,
+	})
+}
+
+func CreateHoliday(c *gin.Context) {
+	var input struct {
+		HolidayDate string `json:"holiday_date" binding:"required"`
+		HolidayName string `json:"holiday_name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.HolidayDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "holiday_date must be in YYYY-MM-DD format"})
+		return
+	}
+
+	var existingHoliday models.Holiday
+	if err := config.DB.Where("holiday_date = ?", date).First(&existingHoliday).Error; err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Holiday already exists for this date"})
+		return
+	}
+
+	holiday := models.Holiday{
+		HolidayDate: date,
+		HolidayName: input.HolidayName,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := config.DB.Create(&holiday).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create holiday"})
+		return
+	}
+	invalidateBusinessCalendar()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Holiday created successfully",
+		"holiday": holiday,
+	})
+}
+
+func DeleteHoliday(c *gin.Context) {
+	var holiday models.Holiday
+	if err := config.DB.First(&holiday, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+
+	if err := config.DB.Delete(&holiday).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete holiday"})
+		return
+	}
+	invalidateBusinessCalendar()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday deleted successfully"})
+}
+
+// ImportHolidays loads a holiday file in the same format as HOLIDAY_FILE.
+// Dates already in the calendar take the name from the file.
+func ImportHolidays(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Holiday file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open holiday file"})
+		return
+	}
+	defer file.Close()
+
+	entries, err := utils.ParseHolidayFile(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse holiday file: " + err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	for _, entry := range entries {
+		holiday := models.Holiday{
+			HolidayDate: entry.Date,
+			HolidayName: entry.Name,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "holiday_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"holiday_name", "updated_at"}),
+		}).Create(&holiday).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save holidays"})
+			return
+		}
+	}
+
+	tx.Commit()
+	invalidateBusinessCalendar()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Holidays imported successfully",
+		"imported": len(entries),
+	})
+}
@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxKYCDocumentSize = 5 << 20
+
+var kycContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+func GetMyProfile(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	respondBorrowerProfile(c, principal.UserID)
+}
+
+// UpsertMyProfile saves the borrower's underwriting details. Changing the NIK
+// or date of birth sends a verified or pending profile back to UNVERIFIED.
+func UpsertMyProfile(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		NIK              string  `json:"nik" binding:"required"`
+		PhoneNumber      string  `json:"phone_number" binding:"required"`
+		DateOfBirth      string  `json:"date_of_birth" binding:"required"`
+		Address          string  `json:"address" binding:"required"`
+		EmploymentStatus string  `json:"employment_status" binding:"required"`
+		EmployerName     string  `json:"employer_name"`
+		MonthlyIncome    float64 `json:"monthly_income" binding:"gte=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dob, err := time.ParseInLocation("2006-01-02", input.DateOfBirth, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_of_birth must be in YYYY-MM-DD format"})
+		return
+	}
+	if dob.AddDate(17, 0, 0).After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Borrower must be at least 17 years old"})
+		return
+	}
+
+	if err := utils.ValidateNIK(input.NIK, dob); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	phone, err := utils.NormalizePhoneNumber(input.PhoneNumber)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employmentStatus := strings.ToUpper(input.EmploymentStatus)
+	if !utils.ValidEmploymentStatus(employmentStatus) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employment status"})
+		return
+	}
+	if (employmentStatus == "EMPLOYED" || employmentStatus == "SELF_EMPLOYED") && input.EmployerName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employer_name is required for employed borrowers"})
+		return
+	}
+
+	var existing int64
+	if err := config.DB.Model(&models.BorrowerProfile{}).
+		Where("nik = ? AND user_id <> ?", input.NIK, principal.UserID).
+		Count(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check NIK"})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "NIK is already registered to another user"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var profile models.BorrowerProfile
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", principal.UserID).First(&profile).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = models.BorrowerProfile{UserID: principal.UserID, KYCStatus: "UNVERIFIED", CreatedAt: time.Now()}
+	} else if profile.NIK != input.NIK || !profile.DateOfBirth.Equal(dob) {
+		profile.KYCStatus = "UNVERIFIED"
+		profile.KYCReviewedBy = nil
+		profile.KYCReviewedAt = nil
+	}
+
+	profile.NIK = input.NIK
+	profile.PhoneNumber = phone
+	profile.DateOfBirth = dob
+	profile.Address = input.Address
+	profile.EmploymentStatus = employmentStatus
+	profile.EmployerName = input.EmployerName
+	profile.MonthlyIncome = input.MonthlyIncome
+	profile.UpdatedAt = time.Now()
+
+	if err := tx.Save(&profile).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save profile"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile saved successfully",
+		"profile": profile,
+	})
+}
+
+func UploadKYCDocument(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	documentType := strings.ToUpper(c.PostForm("document_type"))
+	if !utils.ValidKYCDocumentType(documentType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document type"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document file is required"})
+		return
+	}
+	if fileHeader.Size > maxKYCDocumentSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document file must not exceed 5 MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open document file"})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxKYCDocumentSize+1))
+	if err != nil || len(content) > maxKYCDocumentSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read document file"})
+		return
+	}
+
+	// Trust the bytes rather than the uploader's filename or header.
+	contentType := http.DetectContentType(content)
+	extension, allowed := kycContentTypes[contentType]
+	if !allowed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document must be a JPEG, PNG or PDF file"})
+		return
+	}
+
+	checksum := sha256.Sum256(content)
+	key := utils.GenerateDocumentKey(principal.UserID, documentType, extension)
+
+	size, err := config.DocumentStore.Put(key, bytes.NewReader(content))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store document"})
+		return
+	}
+
+	document := models.KYCDocument{
+		UserID:         principal.UserID,
+		DocumentType:   documentType,
+		StorageKey:     key,
+		FileName:       filepath.Base(fileHeader.Filename),
+		ContentType:    contentType,
+		FileSize:       size,
+		Checksum:       hex.EncodeToString(checksum[:]),
+		DocumentStatus: "PENDING",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := config.DB.Create(&document).Error; err != nil {
+		config.DocumentStore.Delete(key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record document"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Document uploaded successfully",
+		"document": document,
+	})
+}
+
+// SubmitKYC asks for the borrower's KYC to be reviewed once the profile and
+// every required document are in place.
+func SubmitKYC(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var profile models.BorrowerProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", principal.UserID).First(&profile).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Profile must be completed before submitting KYC"})
+		return
+	}
+
+	if profile.KYCStatus == "PENDING" || profile.KYCStatus == "VERIFIED" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "KYC is already " + strings.ToLower(profile.KYCStatus)})
+		return
+	}
+
+	for _, documentType := range utils.RequiredKYCDocuments {
+		var count int64
+		if err := tx.Model(&models.KYCDocument{}).
+			Where("user_id = ? AND document_type = ? AND document_status <> ?", principal.UserID, documentType, "REJECTED").
+			Count(&count).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check documents"})
+			return
+		}
+		if count == 0 {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{"error": documentType + " document is required"})
+			return
+		}
+	}
+
+	profile.KYCStatus = "PENDING"
+	profile.KYCRejectionReason = ""
+	profile.UpdatedAt = time.Now()
+
+	if err := tx.Save(&profile).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit KYC"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "KYC submitted successfully",
+		"profile": profile,
+	})
+}
+
+func GetBorrowerProfile(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	respondBorrowerProfile(c, user.ID)
+}
+
+func respondBorrowerProfile(c *gin.Context, userID uint64) {
+	var profile models.BorrowerProfile
+	if err := config.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		profile = models.BorrowerProfile{UserID: userID, KYCStatus: "UNVERIFIED"}
+	}
+
+	var documents []models.KYCDocument
+	if err := config.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&documents).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Profile fetched successfully",
+		"profile":   profile,
+		"documents": documents,
+	})
+}
+
+func ReviewKYCDocument(c *gin.Context) {
+	var input struct {
+		Status string `json:"status" binding:"required,oneof=VERIFIED REJECTED"`
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Status == "REJECTED" && input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required to reject a document"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var document models.KYCDocument
+	if err := config.DB.First(&document, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	now := time.Now()
+	document.DocumentStatus = input.Status
+	document.RejectionReason = input.Reason
+	document.ReviewedBy = &principal.UserID
+	document.ReviewedAt = &now
+	document.UpdatedAt = now
+
+	if err := config.DB.Save(&document).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review document"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Document reviewed successfully",
+		"document": document,
+	})
+}
+
+func DownloadKYCDocument(c *gin.Context) {
+	var document models.KYCDocument
+	if err := config.DB.First(&document, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	content, err := config.DocumentStore.Open(document.StorageKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open document"})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, document.FileSize, document.ContentType, content, map[string]string{
+		"Content-Disposition": `attachment; filename="` + strings.ReplaceAll(document.FileName, `"`, "") + `"`,
+	})
+}
+
+// ReviewKYC records the final KYC decision for a borrower. Verification needs
+// a verified document of every required type.
+func ReviewKYC(c *gin.Context) {
+	var input struct {
+		Status string `json:"status" binding:"required,oneof=VERIFIED REJECTED"`
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Status == "REJECTED" && input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required to reject KYC"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var profile models.BorrowerProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", c.Param("user_id")).First(&profile).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	if input.Status == "VERIFIED" {
+		for _, documentType := range utils.RequiredKYCDocuments {
+			var count int64
+			if err := tx.Model(&models.KYCDocument{}).
+				Where("user_id = ? AND document_type = ? AND document_status = ?", profile.UserID, documentType, "VERIFIED").
+				Count(&count).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check documents"})
+				return
+			}
+			if count == 0 {
+				tx.Rollback()
+				c.JSON(http.StatusBadRequest, gin.H{"error": "A verified " + documentType + " document is required"})
+				return
+			}
+		}
+	}
+
+	now := time.Now()
+	profile.KYCStatus = input.Status
+	profile.KYCRejectionReason = input.Reason
+	profile.KYCReviewedBy = &principal.UserID
+	profile.KYCReviewedAt = &now
+	profile.UpdatedAt = now
+
+	if err := tx.Save(&profile).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review KYC"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "KYC reviewed successfully",
+		"profile": profile,
+	})
+}
+
+// SetBorrowerRiskGrade lets an underwriter grade a borrower. The grade takes
+// precedence over the decision engine's until it is cleared with an empty
+// risk_grade.
+func SetBorrowerRiskGrade(c *gin.Context) {
+	var input struct {
+		RiskGrade string `json:"risk_grade"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	riskGrade := strings.ToUpper(input.RiskGrade)
+	if riskGrade != "" && !utils.ValidRiskGrade(riskGrade) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk grade"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var profile models.BorrowerProfile
+	if err := config.DB.Where("user_id = ?", c.Param("user_id")).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	now := time.Now()
+	profile.RiskGrade = riskGrade
+	profile.RiskGradedBy = &principal.UserID
+	profile.RiskGradedAt = &now
+	profile.UpdatedAt = now
+
+	if err := config.DB.Save(&profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set risk grade"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Risk grade set successfully",
+		"profile": profile,
+	})
+}
+
+// resolveRiskGrade returns the underwriter's grade for the borrower when one
+// is set, and fallback otherwise.
+func resolveRiskGrade(tx *gorm.DB, userID uint64, fallback string) (string, error) {
+	var profile models.BorrowerProfile
+	err := tx.Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && profile.RiskGrade == "") {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return profile.RiskGrade, nil
+}
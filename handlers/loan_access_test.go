@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"go-billing-engine/middlewares"
+	"go-billing-engine/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoanAccessByRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   uint64
+		role     string
+		partyRow bool
+		want     int
+	}{
+		{name: "admin sees another borrower's loan", userID: 1, role: "ADMIN", want: http.StatusOK},
+		{name: "borrower sees own loan", userID: 2, role: "BORROWER", want: http.StatusOK},
+		{name: "co-borrower sees the loan", userID: 3, role: "BORROWER", partyRow: true, want: http.StatusOK},
+		{name: "other borrower gets not found", userID: 3, role: "BORROWER", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+
+			mock.ExpectQuery(`FROM "users"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(tt.userID, tt.role))
+			mock.ExpectQuery(`FROM "loans"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "loan_status"}).AddRow(7, 2, "ACTIVE"))
+			if tt.role != "ADMIN" && tt.userID != 2 {
+				count := 0
+				if tt.partyRow {
+					count = 1
+				}
+				mock.ExpectQuery(`FROM "loan_parties"`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+			}
+			if tt.want == http.StatusOK {
+				mock.ExpectQuery(`FROM "loan_restructures"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+
+			token, err := utils.GenerateJWT(tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := serve(http.MethodGet, "/loans/:id/restructures", "/loans/7/restructures", "",
+				http.Header{"Authorization": {"Bearer " + token}},
+				middlewares.AuthMiddleware(), GetLoanRestructures)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
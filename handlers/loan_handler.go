@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -31,21 +31,27 @@ type InstallmentDTO struct {
 	PrincipalAmount   float64   `json:"principal_amount"`
 	OutstandingAmount float64   `json:"outstanding_amount"`
 	DueDate           time.Time `json:"due_date"`
-	PaidStatus        string    `json:"paid_status"`
+	PaidStatus        string    `json:"paid_status,omitempty"`
 }
 
 type LoanDetailDTO struct {
-	ID           uint64           `json:"id"`
-	UserID       uint64           `json:"user_id"`
-	LoanCode     string           `json:"loan_code"`
-	LoanStatus   string           `json:"loan_status"`
-	LoanAmount   float64          `json:"loan_amount"`
-	LoanLength   int              `json:"loan_length"`
-	NTFTotal     float64          `json:"ntf_total"`
-	AdminTotal   float64          `json:"admin_total"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
-	Installments []InstallmentDTO `json:"installments"`
+	ID                    uint64                   `json:"id"`
+	UserID                uint64                   `json:"user_id"`
+	LoanCode              string                   `json:"loan_code"`
+	LoanStatus            string                   `json:"loan_status"`
+	LoanAmount            float64                  `json:"loan_amount"`
+	LoanLength            int                      `json:"loan_length"`
+	NTFTotal              float64                  `json:"ntf_total"`
+	AdminTotal            float64                  `json:"admin_total"`
+	RiskGrade             string                   `json:"risk_grade,omitempty"`
+	InterestRate          *float64                 `json:"interest_rate"`
+	AdminRate             *float64                 `json:"admin_rate"`
+	CreatedAt             time.Time                `json:"created_at"`
+	UpdatedAt             time.Time                `json:"updated_at"`
+	ActiveScheduleVersion int                      `json:"active_schedule_version"`
+	ScheduleVersion       int                      `json:"schedule_version"`
+	ScheduleVersions      []models.ScheduleVersion `json:"schedule_versions"`
+	Installments          []InstallmentDTO         `json:"installments"`
 }
 
 func GetAllLoans(c *gin.Context) {
@@ -65,8 +71,13 @@ func GetAllLoans(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
+	query := config.DB
+	if userID, scoped := borrowerScope(c); scoped {
+		query = loanPartyQuery(query, userID)
+	}
+
 	var loans []models.Loan
-	if err := config.DB.
+	if err := query.
 		Order("created_at desc").
 		Limit(limit).
 		Offset(offset).
@@ -112,9 +123,32 @@ func GetLoanDetail(c *gin.Context) {
 		return
 	}
 
-	var installments []models.Installment
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	version := loan.ScheduleVersion
+	if versionStr := c.Query("version"); versionStr != "" {
+		v, err := strconv.Atoi(versionStr)
+		if err != nil || v < 1 || v > loan.ScheduleVersion {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule version"})
+			return
+		}
+		version = v
+	}
+
+	var scheduleVersions []models.ScheduleVersion
 	if err := config.DB.
 		Where("loan_id = ?", loan.ID).
+		Order("version_number asc").
+		Find(&scheduleVersions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load schedule versions"})
+		return
+	}
+
+	var installments []models.Installment
+	if err := config.DB.
+		Where("loan_id = ? AND schedule_version = ?", loan.ID, version).
 		Order("sequence asc").
 		Find(&installments).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load installments"})
@@ -135,17 +169,23 @@ func GetLoanDetail(c *gin.Context) {
 	}
 
 	response := LoanDetailDTO{
-		ID:           loan.ID,
-		UserID:       loan.UserID,
-		LoanCode:     loan.LoanCode,
-		LoanStatus:   loan.LoanStatus,
-		LoanAmount:   loan.LoanAmount,
-		LoanLength:   loan.LoanLength,
-		NTFTotal:     loan.NTFTotal,
-		AdminTotal:   loan.AdminTotal,
-		CreatedAt:    loan.CreatedAt,
-		UpdatedAt:    loan.UpdatedAt,
-		Installments: installmentDTOs,
+		ID:                    loan.ID,
+		UserID:                loan.UserID,
+		LoanCode:              loan.LoanCode,
+		LoanStatus:            loan.LoanStatus,
+		LoanAmount:            loan.LoanAmount,
+		LoanLength:            loan.LoanLength,
+		NTFTotal:              loan.NTFTotal,
+		AdminTotal:            loan.AdminTotal,
+		RiskGrade:             loan.RiskGrade,
+		InterestRate:          loan.InterestRate,
+		AdminRate:             loan.AdminRate,
+		CreatedAt:             loan.CreatedAt,
+		UpdatedAt:             loan.UpdatedAt,
+		ActiveScheduleVersion: loan.ScheduleVersion,
+		ScheduleVersion:       version,
+		ScheduleVersions:      scheduleVersions,
+		Installments:          installmentDTOs,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -156,8 +196,12 @@ func GetLoanDetail(c *gin.Context) {
 
 func CreateLoan(c *gin.Context) {
 	var input struct {
-		LoanAmount float64 `json:"loan_amount" binding:"required"`
-		LoanLength int     `json:"loan_length" binding:"required"`
+		LoanAmount  float64          `json:"loan_amount" binding:"required"`
+		LoanLength  int              `json:"loan_length" binding:"required"`
+		UserID      uint64           `json:"user_id"`
+		ProductCode string           `json:"product_code"`
+		Parties     []loanPartyInput `json:"parties" binding:"dive"`
+		PromoCode   string           `json:"promo_code"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -165,99 +209,137 @@ func CreateLoan(c *gin.Context) {
 		return
 	}
 
-	userIDFloat, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	userID, ok := resolveBorrowerID(c, input.UserID)
+	if !ok {
 		return
 	}
-	userID := uint64(userIDFloat.(float64))
 
-	var existingLoan models.Loan
-	if err := config.DB.Where("user_id = ? AND loan_status != ?", userID, "CLOSED").First(&existingLoan).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User has an active loan, cannot create new loan"})
+	// A borrower could otherwise make any user liable for their loan, so
+	// only admins and API clients may name parties when it is created.
+	if _, scoped := borrowerScope(c); scoped && len(input.Parties) > 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and API clients can name co-borrowers or guarantors"})
 		return
 	}
 
-	var pricing models.Pricing
-	if err := config.DB.First(&pricing).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No pricing found"})
+	// The decision engine grades the application from a base-priced offer;
+	// the loan itself is priced for the grade it is given.
+	offer, err := prepareLoanOffer(config.DB, input.ProductCode, input.LoanAmount, input.LoanLength, time.Now(), "")
+	if err != nil {
+		respondOfferError(c, err)
 		return
 	}
 
-	loanAmount := input.LoanAmount
-	loanLength := input.LoanLength
-	adminTotal := loanAmount * (pricing.AdminRate / 100)
-	ntfTotal := loanAmount + adminTotal
-	annualInterestRate := pricing.InterestRate / 100
-	weeklyInterestRate := annualInterestRate / 52
-	pmt := utils.PMT(weeklyInterestRate, loanLength, ntfTotal)
-
 	tx := config.DB.Begin()
 
-	generatedLoanCode := utils.GenerateLoanCode()
-
-	loan := models.Loan{
-		UserID:     userID,
-		PricingID:  pricing.ID,
-		LoanCode:   generatedLoanCode,
-		LoanStatus: "ACTIVE",
-		LoanAmount: loanAmount,
-		LoanLength: loanLength,
-		NTFTotal:   ntfTotal,
-		AdminTotal: adminTotal,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	if err := checkLoanEligibility(tx, userID, offer, 0); err != nil {
+		tx.Rollback()
+		respondOfferError(c, err)
+		return
 	}
 
-	if err := tx.Create(&loan).Error; err != nil {
+	decision, err := decideApplication(tx, userID, offer, creditApplication{
+		LoanAmount:  input.LoanAmount,
+		LoanLength:  input.LoanLength,
+		ProductCode: input.ProductCode,
+		Parties:     input.Parties,
+		PromoCode:   input.PromoCode,
+	})
+	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate loan application"})
 		return
 	}
 
-	baseDate := time.Now().AddDate(0, 0, 7)
+	switch decision.Decision {
+	case utils.CreditDecisionReject:
+		tx.Commit()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Loan application rejected", "decision": decision})
+		return
+	case utils.CreditDecisionManualReview:
+		tx.Commit()
+		c.JSON(http.StatusAccepted, gin.H{"message": "Loan application referred for manual review", "decision": decision})
+		return
+	}
 
-	currentNTFTotal := ntfTotal
+	riskGrade, err := resolveRiskGrade(tx, userID, decision.RiskGrade)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve risk grade"})
+		return
+	}
 
-	for i := 1; i <= loanLength; i++ {
-		interest := currentNTFTotal * weeklyInterestRate
-		principal := pmt - interest
-		currentNTFTotal -= principal
+	if riskGrade != "" {
+		offer, err = prepareLoanOffer(tx, input.ProductCode, input.LoanAmount, input.LoanLength, time.Now(), riskGrade)
+		if err != nil {
+			tx.Rollback()
+			respondOfferError(c, err)
+			return
+		}
 
-		installment := models.Installment{
-			LoanID:                loan.ID,
-			UserID:                loan.UserID,
-			Sequence:              i,
-			InstallmentAmount:     pmt,
-			InterestAmount:        interest,
-			PrincipalAmount:       principal,
-			OutstandingAmount:     utils.Max(currentNTFTotal, 0),
-			DueDate:               baseDate.AddDate(0, 0, (i-1)*7),
-			PaidStatus:            "PENDING",
-			PaidAmountInstallment: 0,
-			PaidAmountInterest:    0,
-			PaidAmountPrincipal:   0,
-			CreatedAt:             time.Now(),
-			UpdatedAt:             time.Now(),
+		if err := checkLoanEligibility(tx, userID, offer, 0); err != nil {
+			tx.Rollback()
+			respondOfferError(c, err)
+			return
 		}
+	}
 
-		if err := tx.Create(&installment).Error; err != nil {
+	if input.PromoCode != "" {
+		if err := applyPromo(tx, &offer, input.PromoCode, userID, true); err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create installment"})
+			respondOfferError(c, err)
+			return
+		}
+	}
+
+	loan := models.Loan{UserID: userID}
+	if err := createLoanFromOffer(tx, &loan, offer, input.Parties); err != nil {
+		tx.Rollback()
+		if _, ok := err.(*offerError); ok {
+			respondOfferError(c, err)
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
+		return
+	}
+
+	decision.LoanID = &loan.ID
+	if err := tx.Save(&decision).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record credit decision"})
+		return
 	}
 
 	tx.Commit()
 
+	// The loan stands even if the borrower's credit cannot be applied; the
+	// balance is then left for the next apply-due run.
+	var creditWarning string
+	creditTx := config.DB.Begin()
+	appliedCredit, err := applyCreditBalance(creditTx, loan.UserID, loan.ID)
+	if err == nil {
+		err = creditTx.Commit().Error
+	} else {
+		creditTx.Rollback()
+	}
+	if err != nil {
+		log.Printf("Failed to apply credit balance to loan %d: %v", loan.ID, err)
+		appliedCredit = nil
+		creditWarning = "Credit balance could not be applied to this loan"
+	}
+
 	if err := config.DB.Preload("User").Preload("Pricing").First(&loan, loan.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load loan details"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Loan created successfully",
-		"loan":    loan,
+		"message":        "Loan created successfully",
+		"loan":           loan,
+		"disclosure":     offer.Disclosure,
+		"decision":       decision,
+		"promo":          promoSummary(offer),
+		"applied_credit": appliedCredit,
+		"credit_warning": creditWarning,
 	})
 }
 
@@ -270,6 +352,10 @@ func GetOutstanding(c *gin.Context) {
 		return
 	}
 
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
 	var installments []models.Installment
 	if err := config.DB.
 		Where("loan_id = ? AND paid_status = ?", loanID, "PENDING").
@@ -279,6 +365,18 @@ func GetOutstanding(c *gin.Context) {
 		return
 	}
 
+	calendar, err := loadBusinessCalendar(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load holiday calendar"})
+		return
+	}
+
+	adjustment, err := loanDateAdjustment(config.DB, loan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load loan product"})
+		return
+	}
+
 	var principalTotal float64
 	var interestTotal float64
 	var overdueCount int
@@ -292,7 +390,7 @@ func GetOutstanding(c *gin.Context) {
 		principalTotal += remainingPrincipal
 		interestTotal += remainingInterest
 
-		if inst.DueDate.Before(today) {
+		if effectiveDueDate(calendar, inst.DueDate, adjustment).Before(today) {
 			overdueCount++
 		}
 	}
@@ -323,7 +421,11 @@ func GetOutstanding(c *gin.Context) {
 }
 
 func MakePayment(c *gin.Context) {
-	loanID := c.Param("loan_id")
+	loanID, err := strconv.ParseUint(c.Param("loan_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
 
 	var input struct {
 		PaymentAmount float64 `json:"payment_amount" binding:"required"`
@@ -340,113 +442,28 @@ func MakePayment(c *gin.Context) {
 		return
 	}
 
-	var installments []models.Installment
-	if err := config.DB.
-		Where("loan_id = ? AND paid_status = ?", loanID, "PENDING").
-		Order("sequence asc").
-		Find(&installments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load installments"})
-		return
-	}
-
-	if len(installments) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending installments"})
-		return
-	}
-
-	var totalOutstanding float64
-	for _, inst := range installments {
-		remainingPrincipal := inst.PrincipalAmount - inst.PaidAmountPrincipal
-		remainingInterest := inst.InterestAmount - inst.PaidAmountInterest
-		totalOutstanding += remainingPrincipal + remainingInterest
-	}
-
-	if input.PaymentAmount > totalOutstanding {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Payment amount %.2f exceeds total outstanding %.2f", input.PaymentAmount, totalOutstanding),
-		})
-		return
-	}
-
-	var overdueInstallmentTotal float64
-	today := time.Now()
-
-	for _, inst := range installments {
-		daysOverdue := today.Sub(inst.DueDate).Hours() / 24
-		if daysOverdue >= 14 {
-			overdueInstallmentTotal += inst.InstallmentAmount
-		}
-	}
-
-	if overdueInstallmentTotal > 0 && input.PaymentAmount < overdueInstallmentTotal {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Payment must cover at least overdue amount: %.2f", overdueInstallmentTotal),
-		})
+	if !ensureLoanAccess(c, config.DB, loan) {
 		return
 	}
 
-	remainingAmount := input.PaymentAmount
-
-	for i := 0; i < len(installments) && remainingAmount > 0; i++ {
-		inst := &installments[i]
-
-		payPrincipal := inst.PrincipalAmount - inst.PaidAmountPrincipal
-		payInterest := inst.InterestAmount - inst.PaidAmountInterest
-		payInstallment := payPrincipal + payInterest
-
-		if remainingAmount >= payInstallment {
-			inst.PaidAmountPrincipal = inst.PrincipalAmount
-			inst.PaidAmountInterest = inst.InterestAmount
-			inst.PaidAmountInstallment = inst.InstallmentAmount
-			inst.PaidStatus = "PAID"
-			remainingAmount -= payInstallment
-		} else {
-			ratio := remainingAmount / payInstallment
-			inst.PaidAmountPrincipal += payPrincipal * ratio
-			inst.PaidAmountInterest += payInterest * ratio
-			inst.PaidAmountInstallment += payInstallment * ratio
-			remainingAmount = 0
-		}
-
-		inst.UpdatedAt = time.Now()
-
-		if err := config.DB.Save(inst).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update installment"})
-			return
-		}
-	}
-
-	payment := models.Payment{
-		UserID:        loan.UserID,
-		LoanID:        loan.ID,
-		PaymentCode:   utils.GeneratePaymentCode(),
+	posting := paymentPosting{
+		LoanID:        loanID,
 		PaymentAmount: input.PaymentAmount,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
 	}
-
-	if err := config.DB.Create(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record payment"})
-		return
+	if principal, ok := currentPrincipal(c); ok && principal.IsUser() {
+		posting.PaidBy = &principal.UserID
 	}
 
-	var pendingCount int64
-	if err := config.DB.Model(&models.Installment{}).
-		Where("loan_id = ? AND paid_status = ?", loanID, "PENDING").
-		Count(&pendingCount).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check loan status"})
+	tx := config.DB.Begin()
+
+	payment, err := postPayment(tx, posting)
+	if err != nil {
+		tx.Rollback()
+		respondPostingError(c, err)
 		return
 	}
 
-	if pendingCount == 0 {
-		loan.LoanStatus = "CLOSED"
-		loan.UpdatedAt = time.Now()
-
-		if err := config.DB.Save(&loan).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close loan"})
-			return
-		}
-	}
+	tx.Commit()
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Payment processed successfully",
@@ -463,6 +480,10 @@ func IsDelinquent(c *gin.Context) {
 		return
 	}
 
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
 	var pendingInstallments []models.Installment
 	if err := config.DB.
 		Where("loan_id = ? AND paid_status = ?", loanID, "PENDING").
@@ -472,12 +493,24 @@ func IsDelinquent(c *gin.Context) {
 		return
 	}
 
+	calendar, err := loadBusinessCalendar(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load holiday calendar"})
+		return
+	}
+
+	adjustment, err := loanDateAdjustment(config.DB, loan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load loan product"})
+		return
+	}
+
 	today := time.Now()
 	var overdueInstallments []models.Installment
 	var minimumPayment float64
 
 	for _, inst := range pendingInstallments {
-		if today.Sub(inst.DueDate).Hours()/24 >= 14 {
+		if today.Sub(effectiveDueDate(calendar, inst.DueDate, adjustment)).Hours()/24 >= 14 {
 			overdueInstallments = append(overdueInstallments, inst)
 			minimumPayment += inst.InstallmentAmount
 		}
@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type offerError struct {
+	Status  int
+	Message string
+}
+
+func (e *offerError) Error() string {
+	return e.Message
+}
+
+func respondOfferError(c *gin.Context, err error) {
+	if oe, ok := err.(*offerError); ok {
+		c.JSON(oe.Status, gin.H{"error": oe.Message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare loan offer"})
+}
+
+type creditDisclosure struct {
+	APR               float64 `json:"apr"`
+	EIR               float64 `json:"eir"`
+	NetDisbursement   float64 `json:"net_disbursement"`
+	AdminFee          float64 `json:"admin_fee"`
+	TotalInterest     float64 `json:"total_interest"`
+	TotalRepayment    float64 `json:"total_repayment"`
+	TotalCostOfCredit float64 `json:"total_cost_of_credit"`
+}
+
+type loanOffer struct {
+	Product      models.Product
+	Pricing      models.Pricing
+	RiskGrade    string
+	InterestRate float64
+	AdminRate    float64
+	LoanAmount   float64
+	LoanLength   int
+	AdminTotal   float64
+	NTFTotal     float64
+	StartDate    time.Time
+	Schedule     []utils.ScheduleLine
+	Disclosure   creditDisclosure
+
+	Promo            *models.PromoCode
+	AdminFeeDiscount float64
+	InterestDiscount float64
+}
+
+// prepareLoanOffer prices a loan and builds its schedule and disclosure
+// without writing anything, so quotes and loan creation always agree. An
+// empty productCode falls back to the first pricing with no product rules.
+// A risk grade selects the product's pricing tier for the grade and tenor;
+// products without tiers price every grade from their base pricing.
+func prepareLoanOffer(db *gorm.DB, productCode string, loanAmount float64, loanLength int, startDate time.Time, riskGrade string) (loanOffer, error) {
+	offer := loanOffer{LoanAmount: loanAmount, LoanLength: loanLength, StartDate: startDate, RiskGrade: strings.ToUpper(riskGrade)}
+
+	if productCode != "" {
+		if err := db.Preload("Pricing").
+			Where("product_code = ? AND product_status = ?", strings.ToUpper(productCode), "ACTIVE").
+			First(&offer.Product).Error; err != nil {
+			return offer, &offerError{http.StatusNotFound, "Product not found"}
+		}
+		offer.Pricing = offer.Product.Pricing
+	} else if err := db.First(&offer.Pricing).Error; err != nil {
+		return offer, &offerError{http.StatusInternalServerError, "No pricing found"}
+	}
+
+	offer.InterestRate = offer.Pricing.InterestRate
+	offer.AdminRate = offer.Pricing.AdminRate
+
+	if offer.RiskGrade != "" && offer.Product.ID != 0 {
+		var tiers []models.PricingTier
+		if err := db.Where("product_id = ?", offer.Product.ID).Find(&tiers).Error; err != nil {
+			return offer, &offerError{http.StatusInternalServerError, "Failed to load pricing tiers"}
+		}
+
+		if len(tiers) > 0 {
+			tier, ok := matchPricingTier(tiers, offer.RiskGrade, loanLength)
+			if !ok {
+				return offer, &offerError{
+					http.StatusBadRequest,
+					fmt.Sprintf("No pricing for risk grade %s and %d installments", offer.RiskGrade, loanLength),
+				}
+			}
+			offer.InterestRate = tier.InterestRate
+			offer.AdminRate = tier.AdminRate
+		}
+	}
+
+	if loanAmount <= 0 {
+		return offer, &offerError{http.StatusBadRequest, "Loan amount must be greater than zero"}
+	}
+
+	if loanLength <= offer.Product.InterestOnlyPeriods {
+		return offer, &offerError{http.StatusBadRequest, "Loan length must be longer than the product's interest-only periods"}
+	}
+
+	return offer, priceOffer(db, &offer)
+}
+
+// priceOffer works out the offer's fees, schedule and disclosure from its
+// rates, applying the offer's promo when it has one. An admin fee discount
+// lowers the financed total before the schedule is built; an interest
+// discount is taken off the interest of the discounted installments.
+func priceOffer(db *gorm.DB, offer *loanOffer) error {
+	calendar, err := loadBusinessCalendar(db)
+	if err != nil {
+		return &offerError{http.StatusInternalServerError, "Failed to load holiday calendar"}
+	}
+
+	offer.AdminTotal = offer.LoanAmount * (offer.AdminRate / 100)
+	offer.AdminFeeDiscount = 0
+	if offer.Promo != nil && offer.Promo.DiscountType == "ADMIN_FEE" {
+		offer.AdminFeeDiscount = utils.RoundFloat(offer.AdminTotal*offer.Promo.DiscountPercent/100, 2)
+		offer.AdminTotal -= offer.AdminFeeDiscount
+	}
+	offer.NTFTotal = offer.LoanAmount + offer.AdminTotal
+
+	offer.Schedule = utils.BuildWeeklySchedule(utils.ScheduleTerms{
+		Principal:           offer.NTFTotal,
+		AnnualRate:          offer.InterestRate / 100,
+		DayCount:            offer.Product.DayCountConvention,
+		Periods:             offer.LoanLength,
+		GracePeriods:        offer.Product.GracePeriods,
+		GraceInterestMode:   offer.Product.GraceInterestMode,
+		InterestOnlyPeriods: offer.Product.InterestOnlyPeriods,
+		StartDate:           offer.StartDate,
+		Calendar:            calendar,
+		DateAdjustment:      dateAdjustment(offer.Product),
+	})
+
+	offer.InterestDiscount = 0
+	if offer.Promo != nil && offer.Promo.DiscountType == "INTEREST" {
+		for i := range offer.Schedule {
+			if offer.Promo.InstallmentCount > 0 && i >= offer.Promo.InstallmentCount {
+				break
+			}
+			line := &offer.Schedule[i]
+			discount := utils.RoundFloat(line.InterestAmount*offer.Promo.DiscountPercent/100, 2)
+			line.InterestAmount -= discount
+			line.InstallmentAmount -= discount
+			offer.InterestDiscount += discount
+		}
+		offer.InterestDiscount = utils.RoundFloat(offer.InterestDiscount, 2)
+	}
+
+	offer.Disclosure, err = discloseCreditCost(offer.LoanAmount, offer.AdminTotal, offer.StartDate, offer.Schedule)
+	if err != nil {
+		return &offerError{http.StatusInternalServerError, "Failed to compute APR"}
+	}
+
+	return nil
+}
+
+// discloseCreditCost computes the APR and EIR from what the borrower actually
+// receives and pays: the loan amount out on startDate and each installment
+// back on its due date. The APR is the EIR expressed as a nominal rate
+// compounded weekly, matching the installment frequency.
+func discloseCreditCost(netDisbursement, adminFee float64, startDate time.Time, schedule []utils.ScheduleLine) (creditDisclosure, error) {
+	flows := []utils.CashFlow{{Date: startDate, Amount: -netDisbursement}}
+	var totalRepayment, totalInterest float64
+	for _, line := range schedule {
+		flows = append(flows, utils.CashFlow{Date: line.DueDate, Amount: line.InstallmentAmount})
+		totalRepayment += line.InstallmentAmount
+		totalInterest += line.InterestAmount
+	}
+
+	eir, err := utils.EffectiveAnnualRate(flows)
+	if err != nil {
+		return creditDisclosure{}, err
+	}
+
+	return creditDisclosure{
+		APR:               utils.RoundFloat(utils.NominalAnnualRate(eir, 52)*100, 2),
+		EIR:               utils.RoundFloat(eir*100, 2),
+		NetDisbursement:   utils.RoundFloat(netDisbursement, 2),
+		AdminFee:          utils.RoundFloat(adminFee, 2),
+		TotalInterest:     utils.RoundFloat(totalInterest, 2),
+		TotalRepayment:    utils.RoundFloat(totalRepayment, 2),
+		TotalCostOfCredit: utils.RoundFloat(totalRepayment-netDisbursement, 2),
+	}, nil
+}
+
+// createLoanFromOffer writes the loan, its parties, its first schedule
+// version and its installments, and redeems the offer's promo code. The
+// caller sets UserID and any links on loan beforehand; the borrower is
+// always recorded as the primary party.
+func createLoanFromOffer(tx *gorm.DB, loan *models.Loan, offer loanOffer, parties []loanPartyInput) error {
+	loan.PricingID = offer.Pricing.ID
+	loan.LoanCode = utils.GenerateLoanCode()
+	loan.LoanStatus = "ACTIVE"
+	loan.LoanAmount = offer.LoanAmount
+	loan.LoanLength = offer.LoanLength
+	loan.NTFTotal = offer.NTFTotal
+	loan.AdminTotal = offer.AdminTotal
+	interestRate, adminRate := offer.InterestRate, offer.AdminRate
+	loan.RiskGrade = offer.RiskGrade
+	loan.InterestRate = &interestRate
+	loan.AdminRate = &adminRate
+	loan.CreatedAt = time.Now()
+	loan.UpdatedAt = time.Now()
+	if offer.Product.ID != 0 {
+		loan.ProductID = &offer.Product.ID
+	}
+	if offer.Promo != nil {
+		loan.PromoCodeID = &offer.Promo.ID
+	}
+
+	if err := tx.Create(loan).Error; err != nil {
+		return err
+	}
+
+	if offer.Promo != nil {
+		if err := recordPromoRedemption(tx, *loan, offer); err != nil {
+			return err
+		}
+	}
+
+	if err := addLoanParties(tx, *loan, parties); err != nil {
+		return err
+	}
+
+	if _, err := startScheduleVersion(tx, loan, "ORIGINATION", ""); err != nil {
+		return err
+	}
+
+	return createInstallments(tx, *loan, offer.Schedule)
+}
+
+func QuoteLoan(c *gin.Context) {
+	var input struct {
+		LoanAmount  float64 `json:"loan_amount" binding:"required"`
+		LoanLength  int     `json:"loan_length" binding:"required"`
+		ProductCode string  `json:"product_code"`
+		RiskGrade   string  `json:"risk_grade"`
+		PromoCode   string  `json:"promo_code"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	riskGrade, ok := offerRiskGrade(c, input.RiskGrade)
+	if !ok {
+		return
+	}
+
+	offer, err := prepareLoanOffer(config.DB, input.ProductCode, input.LoanAmount, input.LoanLength, time.Now(), riskGrade)
+	if err != nil {
+		respondOfferError(c, err)
+		return
+	}
+
+	if input.PromoCode != "" {
+		if err := applyPromo(config.DB, &offer, input.PromoCode, 0, false); err != nil {
+			respondOfferError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Loan quote prepared successfully",
+		"quote": gin.H{
+			"product_code":       offer.Product.ProductCode,
+			"loan_amount":        offer.LoanAmount,
+			"loan_length":        offer.LoanLength,
+			"risk_grade":         offer.RiskGrade,
+			"interest_rate":      offer.InterestRate,
+			"admin_rate":         offer.AdminRate,
+			"promo":              promoSummary(offer),
+			"installment_amount": utils.RoundFloat(offer.Schedule[len(offer.Schedule)-1].InstallmentAmount, 2),
+			"disclosure":         offer.Disclosure,
+		},
+	})
+}
+
+// SimulateLoan returns the full repayment table a loan would have, built by
+// the same generator as CreateLoan. Nothing is written to the database.
+func SimulateLoan(c *gin.Context) {
+	var input struct {
+		LoanAmount  float64 `json:"loan_amount" binding:"required"`
+		LoanLength  int     `json:"loan_length" binding:"required"`
+		ProductCode string  `json:"product_code"`
+		RiskGrade   string  `json:"risk_grade"`
+		PromoCode   string  `json:"promo_code"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	riskGrade, ok := offerRiskGrade(c, input.RiskGrade)
+	if !ok {
+		return
+	}
+
+	offer, err := prepareLoanOffer(config.DB, input.ProductCode, input.LoanAmount, input.LoanLength, time.Now(), riskGrade)
+	if err != nil {
+		respondOfferError(c, err)
+		return
+	}
+
+	if input.PromoCode != "" {
+		if err := applyPromo(config.DB, &offer, input.PromoCode, 0, false); err != nil {
+			respondOfferError(c, err)
+			return
+		}
+	}
+
+	installments := make([]InstallmentDTO, 0, len(offer.Schedule))
+	var totalPrincipal float64
+	for _, line := range offer.Schedule {
+		installments = append(installments, InstallmentDTO{
+			Sequence:          line.Sequence,
+			InstallmentAmount: utils.RoundFloat(line.InstallmentAmount, 2),
+			InterestAmount:    utils.RoundFloat(line.InterestAmount, 2),
+			PrincipalAmount:   utils.RoundFloat(line.PrincipalAmount, 2),
+			OutstandingAmount: utils.RoundFloat(line.OutstandingAmount, 2),
+			DueDate:           line.DueDate,
+		})
+		totalPrincipal += line.PrincipalAmount
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Loan simulated successfully",
+		"simulation": gin.H{
+			"product_code":  offer.Product.ProductCode,
+			"loan_amount":   offer.LoanAmount,
+			"loan_length":   offer.LoanLength,
+			"risk_grade":    offer.RiskGrade,
+			"interest_rate": offer.InterestRate,
+			"admin_rate":    offer.AdminRate,
+			"promo":         promoSummary(offer),
+			"admin_total":   utils.RoundFloat(offer.AdminTotal, 2),
+			"ntf_total":     utils.RoundFloat(offer.NTFTotal, 2),
+			"totals": gin.H{
+				"principal": utils.RoundFloat(totalPrincipal, 2),
+				"interest":  offer.Disclosure.TotalInterest,
+				"repayment": offer.Disclosure.TotalRepayment,
+			},
+			"disclosure":   offer.Disclosure,
+			"installments": installments,
+		},
+	})
+}
+
+// offerRiskGrade returns the grade a quote or simulation is priced at.
+// Borrowers are always priced at their own grade, so a requested grade is
+// honoured only for admins and API clients.
+func offerRiskGrade(c *gin.Context, requested string) (string, bool) {
+	if _, ok := currentPrincipal(c); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return "", false
+	}
+
+	if userID, scoped := borrowerScope(c); scoped {
+		riskGrade, err := resolveRiskGrade(config.DB, userID, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve risk grade"})
+			return "", false
+		}
+		return riskGrade, true
+	}
+
+	return requested, true
+}
+
+// promoSummary describes the discount an offer's promo code gives, or nil
+// when the offer has none.
+func promoSummary(offer loanOffer) gin.H {
+	if offer.Promo == nil {
+		return nil
+	}
+	return gin.H{
+		"promo_code":         offer.Promo.PromoCode,
+		"campaign_name":      offer.Promo.CampaignName,
+		"admin_fee_discount": offer.AdminFeeDiscount,
+		"interest_discount":  offer.InterestDiscount,
+		"total_discount":     utils.RoundFloat(offer.AdminFeeDiscount+offer.InterestDiscount, 2),
+	}
+}
+
+// matchPricingTier finds the tier for grade whose tenor band contains
+// loanLength.
+func matchPricingTier(tiers []models.PricingTier, grade string, loanLength int) (models.PricingTier, bool) {
+	for _, tier := range tiers {
+		if tier.RiskGrade == grade && tier.MinTenor <= loanLength && loanLength <= tier.MaxTenor {
+			return tier, true
+		}
+	}
+	return models.PricingTier{}, false
+}
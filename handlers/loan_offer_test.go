@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go-billing-engine/utils"
+)
+
+func TestDiscloseCreditCost(t *testing.T) {
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	weekly := func(amounts ...[2]float64) []utils.ScheduleLine {
+		lines := make([]utils.ScheduleLine, len(amounts))
+		for i, amount := range amounts {
+			lines[i] = utils.ScheduleLine{
+				Sequence:          i + 1,
+				InstallmentAmount: amount[0],
+				InterestAmount:    amount[1],
+				DueDate:           start.AddDate(0, 0, 7*(i+1)),
+			}
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name     string
+		net, fee float64
+		schedule []utils.ScheduleLine
+		want     creditDisclosure
+	}{
+		{
+			name:     "zero interest, no fee",
+			net:      1000,
+			schedule: weekly([2]float64{250, 0}, [2]float64{250, 0}, [2]float64{250, 0}, [2]float64{250, 0}),
+			want:     creditDisclosure{NetDisbursement: 1000, TotalRepayment: 1000},
+		},
+		{
+			name: "fee only",
+			net:  1000,
+			fee:  100,
+			schedule: []utils.ScheduleLine{
+				{Sequence: 1, InstallmentAmount: 1100, DueDate: start.AddDate(0, 0, 365)},
+			},
+			want: creditDisclosure{
+				APR:               9.54,
+				EIR:               10,
+				NetDisbursement:   1000,
+				AdminFee:          100,
+				TotalRepayment:    1100,
+				TotalCostOfCredit: 100,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := discloseCreditCost(tt.net, tt.fee, start, tt.schedule)
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: disclosure = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscloseCreditCostWeeklyInterest(t *testing.T) {
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	schedule := utils.BuildWeeklySchedule(utils.ScheduleTerms{
+		Principal:  1000,
+		AnnualRate: 0.104,
+		Periods:    52,
+		StartDate:  start,
+	})
+
+	got, err := discloseCreditCost(1000, 0, start, schedule)
+	if err != nil {
+		t.Fatalf("discloseCreditCost error: %v", err)
+	}
+
+	// 10.4% a year on the 52-week basis is 0.2% a week, which compounds to
+	// an EIR of 1.002^(365/7) - 1 on the 365-day basis.
+	if got.EIR != 10.98 {
+		t.Errorf("EIR = %v, want 10.98", got.EIR)
+	}
+	if got.APR != 10.43 {
+		t.Errorf("APR = %v, want 10.43", got.APR)
+	}
+}
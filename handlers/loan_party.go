@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go-billing-engine/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type loanPartyInput struct {
+	UserID    uint64 `json:"user_id" binding:"required"`
+	PartyRole string `json:"party_role" binding:"required,oneof=CO_BORROWER GUARANTOR"`
+}
+
+// addLoanParties records the loan's primary borrower followed by any extra
+// parties. Extra parties must be existing users other than the borrower and
+// may appear only once.
+func addLoanParties(tx *gorm.DB, loan models.Loan, extra []loanPartyInput) error {
+	parties := []models.LoanParty{{LoanID: loan.ID, UserID: loan.UserID, PartyRole: "PRIMARY"}}
+
+	seen := map[uint64]bool{loan.UserID: true}
+	for _, input := range extra {
+		if seen[input.UserID] {
+			return &offerError{http.StatusBadRequest, "A user can only be one party to a loan"}
+		}
+		seen[input.UserID] = true
+
+		var user models.User
+		if err := tx.First(&user, input.UserID).Error; err != nil {
+			return &offerError{http.StatusNotFound, "Loan party user not found"}
+		}
+
+		parties = append(parties, models.LoanParty{LoanID: loan.ID, UserID: user.ID, PartyRole: input.PartyRole})
+	}
+
+	for i := range parties {
+		parties[i].CreatedAt = time.Now()
+		parties[i].UpdatedAt = time.Now()
+	}
+
+	return tx.Create(&parties).Error
+}
+
+// borrowerScope returns the user whose loans bound the request. Admins and
+// API clients are not bound to any borrower.
+func borrowerScope(c *gin.Context) (uint64, bool) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() || principal.Role == "ADMIN" {
+		return 0, false
+	}
+	return principal.UserID, true
+}
+
+// loanPartyQuery limits a loan query to loans the user is a party to. The
+// loan's own user_id is always a party, which covers loans created before
+// parties were recorded.
+func loanPartyQuery(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Where("user_id = ? OR id IN (?)", userID,
+		db.Session(&gorm.Session{NewDB: true}).Model(&models.LoanParty{}).Select("loan_id").Where("user_id = ?", userID))
+}
+
+func isLoanParty(db *gorm.DB, loan models.Loan, userID uint64) (bool, error) {
+	if loan.UserID == userID {
+		return true, nil
+	}
+
+	var count int64
+	err := db.Model(&models.LoanParty{}).
+		Where("loan_id = ? AND user_id = ?", loan.ID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ensureLoanAccess answers 404 when a borrower-scoped caller is not a party
+// to the loan, so other borrowers' loans look the same as missing ones.
+func ensureLoanAccess(c *gin.Context, db *gorm.DB, loan models.Loan) bool {
+	userID, scoped := borrowerScope(c)
+	if !scoped {
+		return true
+	}
+
+	party, err := isLoanParty(db, loan, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check loan access"})
+		return false
+	}
+	if !party {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return false
+	}
+	return true
+}
+
+// loanInDefault reports whether the loan has been written off or has an
+// installment at least 14 days past its effective due date, the point at
+// which guarantors become liable.
+func loanInDefault(db *gorm.DB, loan models.Loan, asOf time.Time) (bool, error) {
+	if loan.LoanStatus == "WRITTEN_OFF" {
+		return true, nil
+	}
+
+	var installments []models.Installment
+	if err := db.
+		Where("loan_id = ? AND paid_status = ? AND due_date < ?", loan.ID, "PENDING", asOf).
+		Find(&installments).Error; err != nil {
+		return false, err
+	}
+
+	calendar, err := loadBusinessCalendar(db)
+	if err != nil {
+		return false, err
+	}
+
+	adjustment, err := loanDateAdjustment(db, loan)
+	if err != nil {
+		return false, err
+	}
+
+	for _, inst := range installments {
+		if asOf.Sub(effectiveDueDate(calendar, inst.DueDate, adjustment)).Hours()/24 >= 14 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type loanContactDTO struct {
+	UserID       uint64 `json:"user_id"`
+	FullName     string `json:"full_name"`
+	EmailAddress string `json:"email_address"`
+	PartyRole    string `json:"party_role"`
+	Liable       bool   `json:"liable"`
+}
+
+func GetLoanParties(c *gin.Context) {
+	var loan models.Loan
+	if err := config.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	parties, err := loanParties(config.DB, loan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loan parties"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Loan parties fetched successfully",
+		"parties": parties,
+	})
+}
+
+func AddLoanParty(c *gin.Context) {
+	var input loanPartyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var loan models.Loan
+	if err := config.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if loan.LoanStatus != "ACTIVE" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parties can only be added to active loans"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	party, err := isLoanParty(config.DB, loan, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check loan parties"})
+		return
+	}
+	if party {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a party to this loan"})
+		return
+	}
+
+	loanParty := models.LoanParty{
+		LoanID:    loan.ID,
+		UserID:    user.ID,
+		PartyRole: input.PartyRole,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := config.DB.Create(&loanParty).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add loan party"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Loan party added successfully",
+		"party":   loanParty,
+	})
+}
+
+func RemoveLoanParty(c *gin.Context) {
+	var loanParty models.LoanParty
+	err := config.DB.Where("loan_id = ? AND user_id = ?", c.Param("id"), c.Param("user_id")).First(&loanParty).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan party not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loan party"})
+		return
+	}
+
+	if loanParty.PartyRole == "PRIMARY" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The primary borrower cannot be removed"})
+		return
+	}
+
+	if err := config.DB.Delete(&loanParty).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove loan party"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Loan party removed successfully",
+	})
+}
+
+// GetLoanContacts lists everyone collections may contact about a loan.
+// Borrowers are always liable; guarantors only once the loan is in default.
+func GetLoanContacts(c *gin.Context) {
+	var loan models.Loan
+	if err := config.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	parties, err := loanParties(config.DB, loan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loan parties"})
+		return
+	}
+
+	inDefault, err := loanInDefault(config.DB, loan, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check loan default"})
+		return
+	}
+
+	contacts := make([]loanContactDTO, 0, len(parties))
+	for _, party := range parties {
+		contacts = append(contacts, loanContactDTO{
+			UserID:       party.UserID,
+			FullName:     party.User.FullName,
+			EmailAddress: party.User.EmailAddress,
+			PartyRole:    party.PartyRole,
+			Liable:       party.PartyRole != "GUARANTOR" || inDefault,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Loan contacts fetched successfully",
+		"in_default": inDefault,
+		"contacts":   contacts,
+	})
+}
+
+// loanParties returns the loan's recorded parties, falling back to the loan's
+// own borrower as primary for loans created before parties were recorded.
+func loanParties(db *gorm.DB, loan models.Loan) ([]models.LoanParty, error) {
+	var parties []models.LoanParty
+	if err := db.Preload("User").
+		Where("loan_id = ?", loan.ID).
+		Order("id asc").
+		Find(&parties).Error; err != nil {
+		return nil, err
+	}
+
+	for _, party := range parties {
+		if party.PartyRole == "PRIMARY" {
+			return parties, nil
+		}
+	}
+
+	primary := models.LoanParty{LoanID: loan.ID, UserID: loan.UserID, PartyRole: "PRIMARY"}
+	if err := db.First(&primary.User, loan.UserID).Error; err != nil {
+		return nil, err
+	}
+	return append([]models.LoanParty{primary}, parties...), nil
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"gorm.io/gorm"
+)
+
+// createInstallments writes schedule as the installments of the loan's current
+// schedule version.
+func createInstallments(tx *gorm.DB, loan models.Loan, schedule []utils.ScheduleLine) error {
+	for _, line := range schedule {
+		installment := models.Installment{
+			LoanID:                loan.ID,
+			UserID:                loan.UserID,
+			ScheduleVersion:       loan.ScheduleVersion,
+			Sequence:              line.Sequence,
+			InstallmentAmount:     line.InstallmentAmount,
+			InterestAmount:        line.InterestAmount,
+			PrincipalAmount:       line.PrincipalAmount,
+			OutstandingAmount:     line.OutstandingAmount,
+			DueDate:               line.DueDate,
+			PaidStatus:            "PENDING",
+			PaidAmountInstallment: 0,
+			PaidAmountInterest:    0,
+			PaidAmountPrincipal:   0,
+			CreatedAt:             time.Now(),
+			UpdatedAt:             time.Now(),
+		}
+
+		if err := tx.Create(&installment).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// currentInterestRate returns the annual rate the loan is charged today: the
+// rate set by its latest restructuring, or the rate it was originated at.
+// Loans booked before rates were kept on the loan fall back to their pricing.
+func currentInterestRate(tx *gorm.DB, loan models.Loan) (float64, error) {
+	var restructure models.LoanRestructure
+	err := tx.Where("loan_id = ?", loan.ID).Order("new_version desc").First(&restructure).Error
+	if err == nil {
+		return restructure.NewInterestRate, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return 0, err
+	}
+
+	if loan.InterestRate != nil {
+		return *loan.InterestRate, nil
+	}
+
+	var pricing models.Pricing
+	if err := tx.First(&pricing, loan.PricingID).Error; err != nil {
+		return 0, err
+	}
+	return pricing.InterestRate, nil
+}
+
+// startScheduleVersion supersedes the loan's active schedule version and opens
+// the next one. The caller writes the new version's installments and saves
+// the loan afterwards.
+func startScheduleVersion(tx *gorm.DB, loan *models.Loan, changeType, note string) (models.ScheduleVersion, error) {
+	now := time.Now()
+
+	if err := tx.Model(&models.ScheduleVersion{}).
+		Where("loan_id = ? AND version_status = ?", loan.ID, "ACTIVE").
+		Updates(map[string]interface{}{"version_status": "SUPERSEDED", "superseded_at": now}).Error; err != nil {
+		return models.ScheduleVersion{}, err
+	}
+
+	versionNumber := 1
+	if changeType != "ORIGINATION" {
+		versionNumber = loan.ScheduleVersion + 1
+	}
+
+	version := models.ScheduleVersion{
+		LoanID:        loan.ID,
+		VersionNumber: versionNumber,
+		VersionStatus: "ACTIVE",
+		ChangeType:    changeType,
+		Note:          note,
+		CreatedAt:     now,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return models.ScheduleVersion{}, err
+	}
+
+	loan.ScheduleVersion = versionNumber
+	return version, nil
+}
+
+// calendarCacheTTL bounds how long another instance's holiday edits can go
+// unseen; edits made through this instance invalidate the cache at once.
+const calendarCacheTTL = 5 * time.Minute
+
+var calendarCache struct {
+	sync.Mutex
+	calendar *utils.BusinessCalendar
+	loadedAt time.Time
+}
+
+// loadBusinessCalendar returns the holiday calendar, reading the holidays
+// table only when the cached copy is missing or stale.
+func loadBusinessCalendar(tx *gorm.DB) (*utils.BusinessCalendar, error) {
+	calendarCache.Lock()
+	defer calendarCache.Unlock()
+
+	if calendarCache.calendar != nil && time.Since(calendarCache.loadedAt) < calendarCacheTTL {
+		return calendarCache.calendar, nil
+	}
+
+	var holidays []models.Holiday
+	if err := tx.Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, 0, len(holidays))
+	for _, holiday := range holidays {
+		dates = append(dates, holiday.HolidayDate)
+	}
+
+	calendarCache.calendar = utils.NewBusinessCalendar(dates)
+	calendarCache.loadedAt = time.Now()
+	return calendarCache.calendar, nil
+}
+
+func invalidateBusinessCalendar() {
+	calendarCache.Lock()
+	calendarCache.calendar = nil
+	calendarCache.Unlock()
+}
+
+// effectiveDueDate moves dueDate off a non-business day using the loan's
+// date adjustment. Borrowers paying by transfer cannot pay on a holiday, so
+// lateness counts from here.
+func effectiveDueDate(calendar *utils.BusinessCalendar, dueDate time.Time, adjustment string) time.Time {
+	return calendar.AdjustDate(dueDate, adjustment)
+}
+
+// loanDateAdjustment is the date adjustment of the loan's product, or the
+// global default for loans without one.
+func loanDateAdjustment(tx *gorm.DB, loan models.Loan) (string, error) {
+	product, err := loanProduct(tx, loan)
+	if err != nil {
+		return "", err
+	}
+	return dateAdjustment(product), nil
+}
+
+// loanProduct returns the product the loan was created under, or the zero
+// product for loans created before products existed.
+func loanProduct(tx *gorm.DB, loan models.Loan) (models.Product, error) {
+	var product models.Product
+	if loan.ProductID == nil {
+		return product, nil
+	}
+	err := tx.First(&product, *loan.ProductID).Error
+	return product, err
+}
+
+func dateAdjustment(product models.Product) string {
+	if product.DateAdjustment != "" {
+		return product.DateAdjustment
+	}
+	return config.DueDateAdjustment
+}
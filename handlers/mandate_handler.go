@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateDebitMandate(c *gin.Context) {
+	var input struct {
+		UserID        uint64  `json:"user_id"`
+		LoanID        uint64  `json:"loan_id"`
+		BankCode      string  `json:"bank_code" binding:"required"`
+		AccountNumber string  `json:"account_number" binding:"required,numeric"`
+		AccountName   string  `json:"account_name" binding:"required"`
+		MaxAmount     float64 `json:"max_amount" binding:"required,gt=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := resolveBorrowerID(c, input.UserID)
+	if !ok {
+		return
+	}
+
+	mandate := models.DebitMandate{
+		UserID:        userID,
+		BankCode:      input.BankCode,
+		AccountNumber: input.AccountNumber,
+		AccountName:   input.AccountName,
+		MaxAmount:     input.MaxAmount,
+		MandateStatus: "ACTIVE",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if input.LoanID != 0 {
+		var loan models.Loan
+		if err := config.DB.Where("id = ? AND user_id = ?", input.LoanID, userID).First(&loan).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+			return
+		}
+		mandate.LoanID = &loan.ID
+	}
+
+	if err := config.DB.Create(&mandate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mandate"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Mandate created successfully",
+		"mandate": mandate,
+	})
+}
+
+func GetMyDebitMandates(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var mandates []models.DebitMandate
+	if err := config.DB.
+		Where("user_id = ?", principal.UserID).
+		Order("created_at desc").
+		Find(&mandates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch mandates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Mandates fetched successfully",
+		"mandates": mandates,
+	})
+}
+
+func RevokeDebitMandate(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var mandate models.DebitMandate
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), principal.UserID).First(&mandate).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mandate not found"})
+		return
+	}
+
+	mandate.MandateStatus = "REVOKED"
+	mandate.UpdatedAt = time.Now()
+
+	if err := config.DB.Save(&mandate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke mandate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mandate revoked successfully",
+		"mandate": mandate,
+	})
+}
+
+func RunCollections(c *gin.Context) {
+	summary, err := runCollectionCycle(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run collection cycle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Collection cycle completed",
+		"summary": summary,
+	})
+}
+
+func GetDebitAttempts(c *gin.Context) {
+	query := config.DB.Order("scheduled_at desc").Limit(200)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("attempt_status = ?", status)
+	}
+
+	var attempts []models.DebitAttempt
+	if err := query.Find(&attempts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch debit attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Debit attempts fetched successfully",
+		"attempts": attempts,
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreatePaymentPartner(c *gin.Context) {
+	var input struct {
+		PartnerCode string `json:"partner_code" binding:"required"`
+		PartnerName string `json:"partner_name" binding:"required"`
+		VAPrefix    string `json:"va_prefix" binding:"omitempty,numeric,max=8"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partnerCode := strings.ToUpper(input.PartnerCode)
+
+	var existingPartner models.PaymentPartner
+	if err := config.DB.Where("partner_code = ?", partnerCode).First(&existingPartner).Error; err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Partner code is already registered"})
+		return
+	}
+
+	secret, err := utils.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate signing secret"})
+		return
+	}
+
+	partner := models.PaymentPartner{
+		PartnerCode:   partnerCode,
+		PartnerName:   input.PartnerName,
+		VAPrefix:      input.VAPrefix,
+		SigningSecret: secret,
+		PartnerStatus: "ACTIVE",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := config.DB.Create(&partner).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment partner"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Payment partner created successfully, share the signing secret over a secure channel",
+		"partner":        partner,
+		"signing_secret": secret,
+	})
+}
+
+func GetAllPaymentPartners(c *gin.Context) {
+	var partners []models.PaymentPartner
+	if err := config.DB.Order("created_at desc").Find(&partners).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payment partners"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Payment partners fetched successfully",
+		"partners": partners,
+	})
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentPosting struct {
+	LoanID            uint64
+	PaymentAmount     float64
+	PaymentChannel    string
+	PartnerCode       string
+	ExternalReference string
+	PaidBy            *uint64
+}
+
+type postingError struct {
+	Status  int
+	Message string
+}
+
+func (e *postingError) Error() string {
+	return e.Message
+}
+
+func respondPostingError(c *gin.Context, err error) {
+	if pe, ok := err.(*postingError); ok {
+		c.JSON(pe.Status, gin.H{"error": pe.Message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process payment"})
+}
+
+// postPayment applies a payment to the loan's pending installments in
+// sequence order and records it. Every channel that moves money into a loan
+// goes through here so the allocation rules stay in one place. The loan row
+// is locked for the duration of tx so concurrent postings are serialised.
+func postPayment(tx *gorm.DB, posting paymentPosting) (models.Payment, error) {
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, posting.LoanID).Error; err != nil {
+		return models.Payment{}, &postingError{http.StatusNotFound, "Loan not found"}
+	}
+
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Order("sequence asc").
+		Find(&installments).Error; err != nil {
+		return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to load installments"}
+	}
+
+	if len(installments) == 0 {
+		return models.Payment{}, &postingError{http.StatusBadRequest, "No pending installments"}
+	}
+
+	var totalOutstanding float64
+	for _, inst := range installments {
+		remainingPrincipal := inst.PrincipalAmount - inst.PaidAmountPrincipal
+		remainingInterest := inst.InterestAmount - inst.PaidAmountInterest
+		totalOutstanding += remainingPrincipal + remainingInterest
+	}
+
+	if posting.PaymentAmount <= 0 {
+		return models.Payment{}, &postingError{http.StatusBadRequest, "Payment amount must be greater than zero"}
+	}
+
+	// Money that has already arrived cannot be turned away, so anything above
+	// the outstanding total is kept on the borrower's credit balance.
+	appliedAmount := posting.PaymentAmount
+	var excessAmount float64
+	if appliedAmount > totalOutstanding {
+		excessAmount = utils.RoundFloat(appliedAmount-totalOutstanding, 2)
+		appliedAmount = totalOutstanding
+	}
+
+	calendar, err := loadBusinessCalendar(tx)
+	if err != nil {
+		return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to load holiday calendar"}
+	}
+
+	adjustment, err := loanDateAdjustment(tx, loan)
+	if err != nil {
+		return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to load loan product"}
+	}
+
+	var overdueInstallmentTotal float64
+	today := time.Now()
+
+	for _, inst := range installments {
+		daysOverdue := today.Sub(effectiveDueDate(calendar, inst.DueDate, adjustment)).Hours() / 24
+		if daysOverdue >= 14 {
+			overdueInstallmentTotal += inst.InstallmentAmount
+		}
+	}
+
+	if overdueInstallmentTotal > 0 && posting.PaymentAmount < overdueInstallmentTotal {
+		return models.Payment{}, &postingError{
+			http.StatusBadRequest,
+			fmt.Sprintf("Payment must cover at least overdue amount: %.2f", overdueInstallmentTotal),
+		}
+	}
+
+	remainingAmount := appliedAmount
+
+	for i := 0; i < len(installments) && remainingAmount > 0; i++ {
+		inst := &installments[i]
+
+		payPrincipal := inst.PrincipalAmount - inst.PaidAmountPrincipal
+		payInterest := inst.InterestAmount - inst.PaidAmountInterest
+		payInstallment := payPrincipal + payInterest
+
+		if remainingAmount >= payInstallment {
+			inst.PaidAmountPrincipal = inst.PrincipalAmount
+			inst.PaidAmountInterest = inst.InterestAmount
+			inst.PaidAmountInstallment = inst.InstallmentAmount
+			inst.PaidStatus = "PAID"
+			remainingAmount -= payInstallment
+		} else {
+			ratio := remainingAmount / payInstallment
+			inst.PaidAmountPrincipal += payPrincipal * ratio
+			inst.PaidAmountInterest += payInterest * ratio
+			inst.PaidAmountInstallment += payInstallment * ratio
+			remainingAmount = 0
+		}
+
+		inst.UpdatedAt = time.Now()
+
+		if err := tx.Save(inst).Error; err != nil {
+			return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to update installment"}
+		}
+	}
+
+	channel := posting.PaymentChannel
+	if channel == "" {
+		channel = "DIRECT"
+	}
+
+	payment := models.Payment{
+		UserID:         loan.UserID,
+		LoanID:         loan.ID,
+		PaymentCode:    utils.GeneratePaymentCode(),
+		PaymentAmount:  posting.PaymentAmount,
+		ExcessAmount:   excessAmount,
+		PaymentChannel: channel,
+		PartnerCode:    posting.PartnerCode,
+		PaidBy:         posting.PaidBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if posting.ExternalReference != "" {
+		reference := posting.ExternalReference
+		payment.ExternalReference = &reference
+	}
+
+	if err := tx.Create(&payment).Error; err != nil {
+		return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to record payment"}
+	}
+
+	if excessAmount > 0 {
+		if _, err := adjustCreditBalance(tx, creditEntry{
+			UserID:          loan.UserID,
+			LoanID:          &loan.ID,
+			PaymentID:       &payment.ID,
+			TransactionType: "OVERPAYMENT",
+			Amount:          excessAmount,
+			Note:            "Excess over outstanding of " + loan.LoanCode,
+		}); err != nil {
+			return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to credit excess payment"}
+		}
+	}
+
+	var pendingCount int64
+	if err := tx.Model(&models.Installment{}).
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Count(&pendingCount).Error; err != nil {
+		return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to check loan status"}
+	}
+
+	if pendingCount == 0 {
+		loan.LoanStatus = "CLOSED"
+		loan.UpdatedAt = time.Now()
+
+		if err := tx.Save(&loan).Error; err != nil {
+			return models.Payment{}, &postingError{http.StatusInternalServerError, "Failed to close loan"}
+		}
+	}
+
+	return payment, nil
+}
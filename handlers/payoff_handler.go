@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type payoffQuote struct {
+	LoanID               uint64    `json:"loan_id"`
+	AsOf                 time.Time `json:"as_of"`
+	OutstandingPrincipal float64   `json:"outstanding_principal"`
+	InterestDue          float64   `json:"interest_due"`
+	AccruedInterest      float64   `json:"accrued_interest"`
+	PayoffAmount         float64   `json:"payoff_amount"`
+}
+
+// loanPayoff works out what settles the loan on asOf: all unpaid principal,
+// the unpaid interest of installments already due, and the interest accrued
+// so far in the current period under the product's day-count convention.
+// Interest for later periods is not charged. The first period of a schedule
+// runs from the day its version was created, or from the loan's creation
+// for loans older than schedule versions, since grace periods and date
+// adjustment can put the first due date anywhere.
+func loanPayoff(tx *gorm.DB, loan models.Loan, asOf time.Time) (payoffQuote, error) {
+	quote := payoffQuote{LoanID: loan.ID, AsOf: asOf}
+
+	product, err := loanProduct(tx, loan)
+	if err != nil {
+		return quote, err
+	}
+
+	// Loans booked before schedule versions were recorded have no version
+	// row; their only schedule started when the loan was created.
+	scheduleStart := loan.CreatedAt
+	var version models.ScheduleVersion
+	err = tx.
+		Where("loan_id = ? AND version_number = ?", loan.ID, loan.ScheduleVersion).
+		First(&version).Error
+	if err == nil {
+		scheduleStart = version.CreatedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return quote, err
+	}
+
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND schedule_version = ?", loan.ID, loan.ScheduleVersion).
+		Order("sequence asc").
+		Find(&installments).Error; err != nil {
+		return quote, err
+	}
+
+	var principal, interestDue, accrued float64
+	accruing := false
+	for i, inst := range installments {
+		if inst.PaidStatus != "PENDING" {
+			continue
+		}
+
+		principal += inst.PrincipalAmount - inst.PaidAmountPrincipal
+		if !inst.DueDate.After(asOf) {
+			interestDue += inst.InterestAmount - inst.PaidAmountInterest
+			continue
+		}
+		if accruing {
+			continue
+		}
+		accruing = true
+
+		periodStart := scheduleStart
+		if i > 0 {
+			periodStart = installments[i-1].DueDate
+		}
+
+		fraction := 0.0
+		if period := utils.YearFraction(periodStart, inst.DueDate, product.DayCountConvention); period > 0 {
+			fraction = utils.YearFraction(periodStart, asOf, product.DayCountConvention) / period
+		}
+		fraction = math.Min(math.Max(fraction, 0), 1)
+		accrued = utils.Max(inst.InterestAmount*fraction-inst.PaidAmountInterest, 0)
+	}
+
+	quote.OutstandingPrincipal = utils.RoundFloat(principal, 2)
+	quote.InterestDue = utils.RoundFloat(interestDue, 2)
+	quote.AccruedInterest = utils.RoundFloat(accrued, 2)
+	quote.PayoffAmount = utils.RoundFloat(principal+interestDue+accrued, 2)
+	return quote, nil
+}
+
+func GetPayoffQuote(c *gin.Context) {
+	var loan models.Loan
+	if err := config.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	if loan.LoanStatus != "ACTIVE" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only active loans have a payoff amount"})
+		return
+	}
+
+	asOf := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+			return
+		}
+		asOf = date
+	}
+
+	quote, err := loanPayoff(config.DB, loan, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute payoff amount"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payoff quote fetched successfully",
+		"payoff":  quote,
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoanPayoffAccrualStart(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	versioned := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		versionRows *sqlmock.Rows
+		want        payoffQuote
+	}{
+		{
+			name:        "from the schedule version",
+			versionRows: sqlmock.NewRows([]string{"id", "loan_id", "version_number", "created_at"}).AddRow(1, 7, 1, versioned),
+			want:        payoffQuote{OutstandingPrincipal: 1000, AccruedInterest: 30, PayoffAmount: 1030},
+		},
+		{
+			name:        "from the loan for loans without a version row",
+			versionRows: sqlmock.NewRows([]string{"id"}),
+			want:        payoffQuote{OutstandingPrincipal: 1000, AccruedInterest: 35, PayoffAmount: 1035},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+
+			mock.ExpectQuery(`FROM "schedule_versions"`).WillReturnRows(tt.versionRows)
+			mock.ExpectQuery(`FROM "installments"`).WillReturnRows(
+				sqlmock.NewRows([]string{"id", "loan_id", "sequence", "principal_amount", "interest_amount", "due_date", "paid_status"}).
+					AddRow(1, 7, 1, 500, 70, time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC), "PENDING").
+					AddRow(2, 7, 2, 500, 70, time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC), "PENDING"))
+
+			loan := models.Loan{ID: 7, ScheduleVersion: 1, LoanStatus: "ACTIVE", CreatedAt: created}
+			got, err := loanPayoff(config.DB, loan, asOf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.LoanID, tt.want.AsOf = 7, asOf
+			if got != tt.want {
+				t.Errorf("loanPayoff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
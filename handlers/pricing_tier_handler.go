@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetPricingTiers(c *gin.Context) {
+	var product models.Product
+	if err := config.DB.Where("product_code = ?", strings.ToUpper(c.Param("code"))).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var tiers []models.PricingTier
+	if err := config.DB.
+		Where("product_id = ?", product.ID).
+		Order("risk_grade asc, min_tenor asc").
+		Find(&tiers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pricing tiers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pricing tiers fetched successfully",
+		"tiers":   tiers,
+	})
+}
+
+// ReplacePricingTiers swaps the product's whole grade × tenor matrix for the
+// one given. Tenor bands for the same grade may not overlap. An empty list
+// removes risk-based pricing from the product.
+func ReplacePricingTiers(c *gin.Context) {
+	var input struct {
+		Tiers []struct {
+			RiskGrade    string  `json:"risk_grade" binding:"required"`
+			MinTenor     int     `json:"min_tenor" binding:"required,gt=0"`
+			MaxTenor     int     `json:"max_tenor" binding:"required,gt=0"`
+			InterestRate float64 `json:"interest_rate" binding:"gte=0"`
+			AdminRate    float64 `json:"admin_rate" binding:"gte=0"`
+		} `json:"tiers" binding:"dive"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := config.DB.Where("product_code = ?", strings.ToUpper(c.Param("code"))).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	tiers := make([]models.PricingTier, 0, len(input.Tiers))
+	for _, entry := range input.Tiers {
+		grade := strings.ToUpper(entry.RiskGrade)
+		if !utils.ValidRiskGrade(grade) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk grade: " + entry.RiskGrade})
+			return
+		}
+		if entry.MinTenor > entry.MaxTenor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_tenor cannot be greater than max_tenor"})
+			return
+		}
+
+		tiers = append(tiers, models.PricingTier{
+			ProductID:    product.ID,
+			RiskGrade:    grade,
+			MinTenor:     entry.MinTenor,
+			MaxTenor:     entry.MaxTenor,
+			InterestRate: entry.InterestRate,
+			AdminRate:    entry.AdminRate,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		})
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		if tiers[i].RiskGrade != tiers[j].RiskGrade {
+			return tiers[i].RiskGrade < tiers[j].RiskGrade
+		}
+		return tiers[i].MinTenor < tiers[j].MinTenor
+	})
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].RiskGrade == tiers[i-1].RiskGrade && tiers[i].MinTenor <= tiers[i-1].MaxTenor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Tenor bands overlap for risk grade %s", tiers[i].RiskGrade)})
+			return
+		}
+	}
+
+	tx := config.DB.Begin()
+
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.PricingTier{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace pricing tiers"})
+		return
+	}
+
+	if len(tiers) > 0 {
+		if err := tx.Create(&tiers).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace pricing tiers"})
+			return
+		}
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pricing tiers updated successfully",
+		"tiers":   tiers,
+	})
+}
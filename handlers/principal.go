@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func currentPrincipal(c *gin.Context) (utils.Principal, bool) {
+	value, exists := c.Get("principal")
+	if !exists {
+		return utils.Principal{}, false
+	}
+	principal, ok := value.(utils.Principal)
+	return principal, ok
+}
+
+// resolveBorrowerID returns the borrower a request acts for. Users always act
+// for themselves; API clients must name the borrower explicitly.
+func resolveBorrowerID(c *gin.Context, requestedUserID uint64) (uint64, bool) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+
+	if principal.IsUser() {
+		return principal.UserID, true
+	}
+
+	if requestedUserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required for API clients"})
+		return 0, false
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, requestedUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return 0, false
+	}
+
+	return user.ID, true
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateProduct(c *gin.Context) {
+	var input struct {
+		ProductCode         string `json:"product_code" binding:"required"`
+		ProductName         string `json:"product_name" binding:"required"`
+		PricingID           uint64 `json:"pricing_id" binding:"required"`
+		GracePeriods        int    `json:"grace_periods" binding:"gte=0"`
+		GraceInterestMode   string `json:"grace_interest_mode" binding:"omitempty,oneof=CAPITALIZED ACCRUED"`
+		InterestOnlyPeriods int    `json:"interest_only_periods" binding:"gte=0"`
+		DateAdjustment      string `json:"date_adjustment" binding:"omitempty,oneof=NONE FOLLOWING MODIFIED_FOLLOWING"`
+		DayCountConvention  string `json:"day_count_convention"`
+		OneActiveLoan       bool   `json:"one_active_loan"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dayCount := strings.ToUpper(input.DayCountConvention)
+	if dayCount == "" {
+		dayCount = "ACT/365"
+	}
+	if !utils.ValidDayCount(dayCount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "day_count_convention must be ACT/365, ACT/360 or 30/360"})
+		return
+	}
+
+	productCode := strings.ToUpper(input.ProductCode)
+
+	var existingProduct models.Product
+	if err := config.DB.Where("product_code = ?", productCode).First(&existingProduct).Error; err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product code is already registered"})
+		return
+	}
+
+	var pricing models.Pricing
+	if err := config.DB.First(&pricing, input.PricingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pricing not found"})
+		return
+	}
+
+	graceInterestMode := input.GraceInterestMode
+	if graceInterestMode == "" {
+		graceInterestMode = "CAPITALIZED"
+	}
+
+	product := models.Product{
+		ProductCode:         productCode,
+		ProductName:         input.ProductName,
+		PricingID:           pricing.ID,
+		GracePeriods:        input.GracePeriods,
+		GraceInterestMode:   graceInterestMode,
+		InterestOnlyPeriods: input.InterestOnlyPeriods,
+		DateAdjustment:      input.DateAdjustment,
+		DayCountConvention:  dayCount,
+		OneActiveLoan:       input.OneActiveLoan,
+		ProductStatus:       "ACTIVE",
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+	}
+
+	if err := config.DB.Create(&product).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
+		return
+	}
+	product.Pricing = pricing
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Product created successfully",
+		"product": product,
+	})
+}
+
+func GetAllProducts(c *gin.Context) {
+	var products []models.Product
+	if err := config.DB.Preload("Pricing").Order("product_code asc").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Products fetched successfully",
+		"products": products,
+	})
+}
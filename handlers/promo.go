@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-billing-engine/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyPromo checks that code can be redeemed against offer and reprices the
+// offer with its discount. A zero userID skips the per-user checks, which is
+// how quotes and simulations preview a code. Pass lock when the redemption
+// will be recorded in tx so concurrent bookings cannot exceed the limits.
+func applyPromo(tx *gorm.DB, offer *loanOffer, code string, userID uint64, lock bool) error {
+	query := tx
+	if lock {
+		query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var promo models.PromoCode
+	if err := query.Where("promo_code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &offerError{http.StatusBadRequest, "Promo code not found"}
+		}
+		return &offerError{http.StatusInternalServerError, "Failed to load promo code"}
+	}
+
+	if err := checkPromoEligibility(tx, promo, offer, userID); err != nil {
+		return err
+	}
+
+	offer.Promo = &promo
+	return priceOffer(tx, offer)
+}
+
+func checkPromoEligibility(tx *gorm.DB, promo models.PromoCode, offer *loanOffer, userID uint64) error {
+	now := time.Now()
+	if promo.PromoStatus != "ACTIVE" || now.Before(promo.ValidFrom) || now.After(promo.ValidUntil) {
+		return &offerError{http.StatusBadRequest, "Promo code is not currently valid"}
+	}
+	if promo.MaxRedemptions > 0 && promo.RedemptionCount >= promo.MaxRedemptions {
+		return &offerError{http.StatusBadRequest, "Promo code has been fully redeemed"}
+	}
+	if promo.ProductID != nil && *promo.ProductID != offer.Product.ID {
+		return &offerError{http.StatusBadRequest, "Promo code does not apply to this product"}
+	}
+	if promo.MinLoanAmount > 0 && offer.LoanAmount < promo.MinLoanAmount {
+		return &offerError{http.StatusBadRequest, "Loan amount is below the promo code's minimum"}
+	}
+	if promo.MaxLoanAmount > 0 && offer.LoanAmount > promo.MaxLoanAmount {
+		return &offerError{http.StatusBadRequest, "Loan amount is above the promo code's maximum"}
+	}
+	if promo.RiskGrades != "" && !containsGrade(promo.RiskGrades, offer.RiskGrade) {
+		return &offerError{http.StatusBadRequest, "Promo code does not apply to this risk grade"}
+	}
+
+	if userID == 0 {
+		return nil
+	}
+
+	if promo.NewBorrowersOnly {
+		var loans int64
+		if err := tx.Model(&models.Loan{}).Where("user_id = ?", userID).Count(&loans).Error; err != nil {
+			return &offerError{http.StatusInternalServerError, "Failed to check borrower history"}
+		}
+		if loans > 0 {
+			return &offerError{http.StatusBadRequest, "Promo code is for new borrowers only"}
+		}
+	}
+
+	var redeemed int64
+	if err := tx.Model(&models.PromoRedemption{}).
+		Where("promo_code_id = ? AND user_id = ?", promo.ID, userID).
+		Count(&redeemed).Error; err != nil {
+		return &offerError{http.StatusInternalServerError, "Failed to check promo redemptions"}
+	}
+	if promo.MaxPerUser > 0 && redeemed >= int64(promo.MaxPerUser) {
+		return &offerError{http.StatusBadRequest, "Promo code has already been used"}
+	}
+
+	return nil
+}
+
+// recordPromoRedemption stores what the offer's promo saved on loan and
+// counts it against the code's limit.
+func recordPromoRedemption(tx *gorm.DB, loan models.Loan, offer loanOffer) error {
+	redemption := models.PromoRedemption{
+		PromoCodeID:      offer.Promo.ID,
+		UserID:           loan.UserID,
+		LoanID:           loan.ID,
+		AdminFeeDiscount: offer.AdminFeeDiscount,
+		InterestDiscount: offer.InterestDiscount,
+		CreatedAt:        time.Now(),
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.PromoCode{}).
+		Where("id = ?", offer.Promo.ID).
+		Updates(map[string]interface{}{
+			"redemption_count": gorm.Expr("redemption_count + 1"),
+			"updated_at":       time.Now(),
+		}).Error
+}
+
+func containsGrade(list, grade string) bool {
+	for _, g := range strings.Split(list, ",") {
+		if strings.TrimSpace(g) == grade {
+			return true
+		}
+	}
+	return false
+}
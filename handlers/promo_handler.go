@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetPromoCodes(c *gin.Context) {
+	var promos []models.PromoCode
+	if err := config.DB.Order("created_at desc").Find(&promos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Promo codes fetched successfully",
+		"promo_codes": promos,
+	})
+}
+
+func CreatePromoCode(c *gin.Context) {
+	var input struct {
+		PromoCode        string   `json:"promo_code" binding:"required"`
+		CampaignName     string   `json:"campaign_name" binding:"required"`
+		DiscountType     string   `json:"discount_type" binding:"required,oneof=ADMIN_FEE INTEREST"`
+		DiscountPercent  float64  `json:"discount_percent" binding:"required,gt=0,lte=100"`
+		InstallmentCount int      `json:"installment_count" binding:"gte=0"`
+		ValidFrom        string   `json:"valid_from" binding:"required"`
+		ValidUntil       string   `json:"valid_until" binding:"required"`
+		MaxRedemptions   int      `json:"max_redemptions" binding:"gte=0"`
+		MaxPerUser       *int     `json:"max_per_user" binding:"omitempty,gte=0"`
+		ProductCode      string   `json:"product_code"`
+		MinLoanAmount    float64  `json:"min_loan_amount" binding:"gte=0"`
+		MaxLoanAmount    float64  `json:"max_loan_amount" binding:"gte=0"`
+		NewBorrowersOnly bool     `json:"new_borrowers_only"`
+		RiskGrades       []string `json:"risk_grades"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validFrom, err := time.Parse("2006-01-02", input.ValidFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid_from must be in YYYY-MM-DD format"})
+		return
+	}
+	validUntil, err := time.Parse("2006-01-02", input.ValidUntil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid_until must be in YYYY-MM-DD format"})
+		return
+	}
+	// The code stays valid through the whole of its last day.
+	validUntil = validUntil.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	if validUntil.Before(validFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid_until must not be before valid_from"})
+		return
+	}
+
+	if input.MaxLoanAmount > 0 && input.MaxLoanAmount < input.MinLoanAmount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_loan_amount must not be below min_loan_amount"})
+		return
+	}
+	if input.DiscountType == "ADMIN_FEE" && input.InstallmentCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "installment_count only applies to INTEREST discounts"})
+		return
+	}
+
+	grades := make([]string, 0, len(input.RiskGrades))
+	for _, grade := range input.RiskGrades {
+		grade = strings.ToUpper(strings.TrimSpace(grade))
+		if !utils.ValidRiskGrade(grade) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk grade " + grade})
+			return
+		}
+		grades = append(grades, grade)
+	}
+
+	promo := models.PromoCode{
+		PromoCode:        strings.ToUpper(strings.TrimSpace(input.PromoCode)),
+		CampaignName:     input.CampaignName,
+		DiscountType:     input.DiscountType,
+		DiscountPercent:  input.DiscountPercent,
+		InstallmentCount: input.InstallmentCount,
+		ValidFrom:        validFrom,
+		ValidUntil:       validUntil,
+		MaxRedemptions:   input.MaxRedemptions,
+		MaxPerUser:       1,
+		MinLoanAmount:    input.MinLoanAmount,
+		MaxLoanAmount:    input.MaxLoanAmount,
+		NewBorrowersOnly: input.NewBorrowersOnly,
+		RiskGrades:       strings.Join(grades, ","),
+		PromoStatus:      "ACTIVE",
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if input.MaxPerUser != nil {
+		promo.MaxPerUser = *input.MaxPerUser
+	}
+
+	if input.ProductCode != "" {
+		var product models.Product
+		if err := config.DB.Where("product_code = ?", input.ProductCode).First(&product).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product not found"})
+			return
+		}
+		promo.ProductID = &product.ID
+	}
+
+	var existing models.PromoCode
+	if err := config.DB.Where("promo_code = ?", promo.PromoCode).First(&existing).Error; err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Promo code already exists"})
+		return
+	}
+
+	if err := config.DB.Create(&promo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create promo code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Promo code created successfully",
+		"promo_code": promo,
+	})
+}
+
+func DeactivatePromoCode(c *gin.Context) {
+	var promo models.PromoCode
+	if err := config.DB.First(&promo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promo code not found"})
+		return
+	}
+
+	if promo.PromoStatus != "ACTIVE" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Promo code is not active"})
+		return
+	}
+
+	promo.PromoStatus = "INACTIVE"
+	promo.UpdatedAt = time.Now()
+	if err := config.DB.Save(&promo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate promo code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Promo code deactivated successfully",
+		"promo_code": promo,
+	})
+}
+
+func GetPromoRedemptions(c *gin.Context) {
+	query := config.DB.Where("promo_code_id = ?", c.Param("id")).Order("created_at desc")
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var redemptions []models.PromoRedemption
+	if err := query.Find(&redemptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promo redemptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Promo redemptions fetched successfully",
+		"redemptions": redemptions,
+	})
+}
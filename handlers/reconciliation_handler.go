@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const reconciliationDateTolerance = 3 * 24 * time.Hour
+
+// bankChannels are the payment channels whose money moves through the bank
+// account. Credit balance applications and refinance settlements are
+// internal postings that never appear on a statement.
+var bankChannels = []string{"BANK_TRANSFER", "VIRTUAL_ACCOUNT", "AUTO_DEBIT", "CALLBACK"}
+
+var loanCodePattern = regexp.MustCompile(`LOA-\d{8}-\d{3}`)
+
+func ImportBankStatement(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statement file is required"})
+		return
+	}
+
+	format := strings.ToUpper(c.PostForm("format"))
+	if format == "" {
+		format = "MT940"
+		if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+			format = "CSV"
+		}
+	}
+	if format != "CSV" && format != "MT940" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be CSV or MT940"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open statement file"})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read statement file"})
+		return
+	}
+
+	fileHash := utils.HashToken(string(content))
+
+	var existingStatement models.BankStatement
+	if err := config.DB.Where("file_hash = ?", fileHash).First(&existingStatement).Error; err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Statement file was already imported as statement %d", existingStatement.ID)})
+		return
+	}
+
+	var parsed utils.ParsedStatement
+	if format == "CSV" {
+		parsed, err = utils.ParseCSVStatement(bytes.NewReader(content))
+	} else {
+		parsed, err = utils.ParseMT940Statement(bytes.NewReader(content))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse statement: " + err.Error()})
+		return
+	}
+
+	if len(parsed.Lines) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statement contains no lines"})
+		return
+	}
+
+	statement := models.BankStatement{
+		AccountNumber: parsed.AccountNumber,
+		FileName:      fileHeader.Filename,
+		FileFormat:    format,
+		FileHash:      fileHash,
+		PeriodStart:   parsed.Lines[0].ValueDate,
+		PeriodEnd:     parsed.Lines[0].ValueDate,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	for _, line := range parsed.Lines {
+		if line.ValueDate.Before(statement.PeriodStart) {
+			statement.PeriodStart = line.ValueDate
+		}
+		if line.ValueDate.After(statement.PeriodEnd) {
+			statement.PeriodEnd = line.ValueDate
+		}
+	}
+
+	tx := config.DB.Begin()
+
+	if err := tx.Create(&statement).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save statement"})
+		return
+	}
+
+	summary := gin.H{"matched": 0, "unmatched": 0, "not_applicable": 0}
+
+	for _, parsedLine := range parsed.Lines {
+		line := models.BankStatementLine{
+			StatementID: statement.ID,
+			LineNumber:  parsedLine.LineNumber,
+			ValueDate:   parsedLine.ValueDate,
+			Direction:   parsedLine.Direction,
+			Amount:      parsedLine.Amount,
+			Reference:   parsedLine.Reference,
+			Description: parsedLine.Description,
+			MatchStatus: "NOT_APPLICABLE",
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		if line.Direction == "CREDIT" {
+			paymentID, err := matchStatementLine(tx, line)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to match statement line"})
+				return
+			}
+
+			line.MatchStatus = "UNMATCHED"
+			if paymentID != 0 {
+				line.MatchStatus = "MATCHED"
+				line.PaymentID = &paymentID
+			}
+		}
+
+		if err := tx.Create(&line).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save statement line"})
+			return
+		}
+
+		key := strings.ToLower(line.MatchStatus)
+		summary[key] = summary[key].(int) + 1
+	}
+
+	tx.Commit()
+
+	missingPayments, err := missingBankLines(statement)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check missing bank lines"})
+		return
+	}
+	summary["missing_bank_lines"] = len(missingPayments)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Statement imported successfully",
+		"statement": statement,
+		"summary":   summary,
+	})
+}
+
+func GetBankStatement(c *gin.Context) {
+	statementID := c.Param("id")
+
+	var statement models.BankStatement
+	if err := config.DB.First(&statement, statementID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Statement not found"})
+		return
+	}
+
+	var lines []models.BankStatementLine
+	if err := config.DB.
+		Where("statement_id = ?", statement.ID).
+		Order("line_number asc").
+		Find(&lines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load statement lines"})
+		return
+	}
+
+	var unmatchedCredits []models.BankStatementLine
+	for _, line := range lines {
+		if line.MatchStatus == "UNMATCHED" {
+			unmatchedCredits = append(unmatchedCredits, line)
+		}
+	}
+
+	missingPayments, err := missingBankLines(statement)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check missing bank lines"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Statement fetched successfully",
+		"statement":          statement,
+		"lines":              lines,
+		"unmatched_credits":  unmatchedCredits,
+		"missing_bank_lines": missingPayments,
+	})
+}
+
+func PostStatementLine(c *gin.Context) {
+	lineID := c.Param("id")
+
+	var input struct {
+		LoanID  uint64 `json:"loan_id"`
+		Confirm bool   `json:"confirm"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !input.Confirm {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Posting a statement line must be confirmed"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var line models.BankStatementLine
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&line, lineID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Statement line not found"})
+		return
+	}
+
+	if line.MatchStatus != "UNMATCHED" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only unmatched credits can be posted"})
+		return
+	}
+
+	loanID := input.LoanID
+	if loanID == 0 {
+		loanCode := loanCodePattern.FindString(line.Reference + " " + line.Description)
+
+		var loan models.Loan
+		if loanCode == "" || tx.Where("loan_code = ?", loanCode).First(&loan).Error != nil {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "loan_id is required, no loan code found on the statement line"})
+			return
+		}
+		loanID = loan.ID
+	}
+
+	payment, err := postPayment(tx, paymentPosting{
+		LoanID:            loanID,
+		PaymentAmount:     line.Amount,
+		PaymentChannel:    "BANK_TRANSFER",
+		PartnerCode:       "BANK_STATEMENT",
+		ExternalReference: fmt.Sprintf("STMT-LINE-%d", line.ID),
+	})
+	if err != nil {
+		tx.Rollback()
+		respondPostingError(c, err)
+		return
+	}
+
+	line.MatchStatus = "POSTED"
+	line.PaymentID = &payment.ID
+	line.UpdatedAt = time.Now()
+
+	if err := tx.Save(&line).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update statement line"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statement line posted successfully",
+		"line":    line,
+		"payment": payment,
+	})
+}
+
+// matchStatementLine looks for a bank payment that is not yet reconciled,
+// first by reference and then by a unique amount within the date tolerance.
+// It returns zero when nothing matches.
+func matchStatementLine(tx *gorm.DB, line models.BankStatementLine) (uint64, error) {
+	from := line.ValueDate.Add(-reconciliationDateTolerance)
+	to := line.ValueDate.Add(reconciliationDateTolerance + 24*time.Hour)
+
+	unreconciled := tx.Model(&models.Payment{}).
+		Where("id NOT IN (?)", tx.Model(&models.BankStatementLine{}).Select("payment_id").Where("payment_id IS NOT NULL")).
+		Where("payment_channel IN ?", bankChannels).
+		Where("payment_amount BETWEEN ? AND ?", line.Amount-0.005, line.Amount+0.005).
+		Where("created_at BETWEEN ? AND ?", from, to)
+
+	if line.Reference != "" {
+		var payment models.Payment
+		err := unreconciled.Session(&gorm.Session{}).
+			Where("payment_code = ? OR external_reference = ?", line.Reference, line.Reference).
+			First(&payment).Error
+		if err == nil {
+			return payment.ID, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+	}
+
+	var candidates []models.Payment
+	if err := unreconciled.Session(&gorm.Session{}).Limit(2).Find(&candidates).Error; err != nil {
+		return 0, err
+	}
+	if len(candidates) == 1 {
+		return candidates[0].ID, nil
+	}
+
+	return 0, nil
+}
+
+// missingBankLines lists bank payments recorded during the statement period
+// that no statement line has been matched to.
+func missingBankLines(statement models.BankStatement) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := config.DB.
+		Where("id NOT IN (?)", config.DB.Model(&models.BankStatementLine{}).Select("payment_id").Where("payment_id IS NOT NULL")).
+		Where("payment_channel IN ?", bankChannels).
+		Where("created_at >= ? AND created_at < ?", statement.PeriodStart, statement.PeriodEnd.Add(24*time.Hour)).
+		Order("created_at asc").
+		Find(&payments).Error
+	return payments, err
+}
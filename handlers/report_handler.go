@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type portfolioStatusRow struct {
+	LoanStatus string  `json:"loan_status"`
+	LoanCount  int64   `json:"loan_count"`
+	LoanAmount float64 `json:"loan_amount"`
+	NTFTotal   float64 `json:"ntf_total"`
+}
+
+func GetPortfolioReport(c *gin.Context) {
+	var byStatus []portfolioStatusRow
+	if err := config.DB.Model(&models.Loan{}).
+		Select("loan_status, COUNT(*) AS loan_count, COALESCE(SUM(loan_amount), 0) AS loan_amount, COALESCE(SUM(ntf_total), 0) AS ntf_total").
+		Group("loan_status").
+		Order("loan_status asc").
+		Scan(&byStatus).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise loans"})
+		return
+	}
+
+	var outstanding struct {
+		Principal float64
+		Interest  float64
+	}
+	if err := config.DB.Model(&models.Installment{}).
+		Select("COALESCE(SUM(principal_amount - paid_amount_principal), 0) AS principal, COALESCE(SUM(interest_amount - paid_amount_interest), 0) AS interest").
+		Where("paid_status = ?", "PENDING").
+		Scan(&outstanding).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise outstanding"})
+		return
+	}
+
+	var writeOffs struct {
+		LoanCount int64
+		Principal float64
+		Interest  float64
+		Fees      float64
+	}
+	if err := config.DB.Model(&models.LoanWriteOff{}).
+		Select("COUNT(*) AS loan_count, COALESCE(SUM(written_off_principal), 0) AS principal, COALESCE(SUM(written_off_interest), 0) AS interest, COALESCE(SUM(written_off_fees), 0) AS fees").
+		Scan(&writeOffs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise write-offs"})
+		return
+	}
+
+	var recovered float64
+	if err := config.DB.Model(&models.LoanRecovery{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&recovered).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise recoveries"})
+		return
+	}
+
+	writtenOffTotal := writeOffs.Principal + writeOffs.Interest + writeOffs.Fees
+	var recoveryRate float64
+	if writtenOffTotal > 0 {
+		recoveryRate = recovered / writtenOffTotal * 100
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Portfolio report fetched successfully",
+		"report": gin.H{
+			"by_status": byStatus,
+			"outstanding": gin.H{
+				"principal": utils.RoundFloat(outstanding.Principal, 2),
+				"interest":  utils.RoundFloat(outstanding.Interest, 2),
+			},
+			"write_offs": gin.H{
+				"loan_count": writeOffs.LoanCount,
+				"principal":  utils.RoundFloat(writeOffs.Principal, 2),
+				"interest":   utils.RoundFloat(writeOffs.Interest, 2),
+				"fees":       utils.RoundFloat(writeOffs.Fees, 2),
+				"total":      utils.RoundFloat(writtenOffTotal, 2),
+			},
+			"recoveries": gin.H{
+				"total":         utils.RoundFloat(recovered, 2),
+				"net_loss":      utils.RoundFloat(writtenOffTotal-recovered, 2),
+				"recovery_rate": utils.RoundFloat(recoveryRate, 2),
+			},
+		},
+	})
+}
+
+type promotionCampaignRow struct {
+	CampaignName     string  `json:"campaign_name"`
+	Redemptions      int64   `json:"redemptions"`
+	AdminFeeDiscount float64 `json:"admin_fee_discount"`
+	InterestDiscount float64 `json:"interest_discount"`
+	TotalDiscount    float64 `json:"total_discount"`
+}
+
+// GetPromotionsReport reports what each campaign's promo codes have cost in
+// waived admin fees and interest.
+func GetPromotionsReport(c *gin.Context) {
+	var campaigns []promotionCampaignRow
+	if err := config.DB.Table("promo_redemptions").
+		Select("promo_codes.campaign_name, COUNT(*) AS redemptions, COALESCE(SUM(promo_redemptions.admin_fee_discount), 0) AS admin_fee_discount, COALESCE(SUM(promo_redemptions.interest_discount), 0) AS interest_discount").
+		Joins("JOIN promo_codes ON promo_codes.id = promo_redemptions.promo_code_id").
+		Group("promo_codes.campaign_name").
+		Order("promo_codes.campaign_name asc").
+		Scan(&campaigns).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise promotions"})
+		return
+	}
+
+	var total float64
+	for i := range campaigns {
+		campaigns[i].AdminFeeDiscount = utils.RoundFloat(campaigns[i].AdminFeeDiscount, 2)
+		campaigns[i].InterestDiscount = utils.RoundFloat(campaigns[i].InterestDiscount, 2)
+		campaigns[i].TotalDiscount = utils.RoundFloat(campaigns[i].AdminFeeDiscount+campaigns[i].InterestDiscount, 2)
+		total += campaigns[i].TotalDiscount
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promotions report fetched successfully",
+		"report": gin.H{
+			"campaigns":      campaigns,
+			"total_discount": utils.RoundFloat(total, 2),
+		},
+	})
+}
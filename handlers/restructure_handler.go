@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// RestructureLoan replaces the loan's pending schedule with a new one built
+// from the outstanding principal plus any interest already overdue and the
+// interest accrued so far in the current period, worked out as for a payoff.
+// The old installments are kept as RESTRUCTURED under the superseded schedule
+// version.
+func RestructureLoan(c *gin.Context) {
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	var input struct {
+		LoanLength   int      `json:"loan_length" binding:"omitempty,gt=0"`
+		InterestRate *float64 `json:"interest_rate" binding:"omitempty,gte=0"`
+		GracePeriods int      `json:"grace_periods" binding:"gte=0"`
+		Reason       string   `json:"reason" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if loan.LoanStatus != "ACTIVE" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only active loans can be restructured"})
+		return
+	}
+
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Order("sequence asc").
+		Find(&installments).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load installments"})
+		return
+	}
+
+	if len(installments) == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending installments"})
+		return
+	}
+
+	now := time.Now()
+	quote, err := loanPayoff(tx, loan, now)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute outstanding balance"})
+		return
+	}
+	outstandingPrincipal := quote.OutstandingPrincipal
+	capitalizedInterest := utils.RoundFloat(quote.InterestDue+quote.AccruedInterest, 2)
+
+	previousRate, err := currentInterestRate(tx, loan)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load current interest rate"})
+		return
+	}
+
+	previousTenor := len(installments)
+	newTenor := previousTenor
+	if input.LoanLength != 0 {
+		newTenor = input.LoanLength
+	}
+	newRate := previousRate
+	if input.InterestRate != nil {
+		newRate = *input.InterestRate
+	}
+
+	if newTenor < previousTenor {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Restructured tenor cannot be shorter than the %d remaining installments", previousTenor)})
+		return
+	}
+
+	if newRate > previousRate {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Restructured interest rate cannot be higher than the current rate"})
+		return
+	}
+
+	if newTenor == previousTenor && newRate == previousRate && input.GracePeriods == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Restructuring must change the tenor, interest rate or grace period"})
+		return
+	}
+
+	if err := tx.Model(&models.Installment{}).
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Updates(map[string]interface{}{"paid_status": "RESTRUCTURED", "updated_at": now}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close current schedule"})
+		return
+	}
+
+	product, err := loanProduct(tx, loan)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load loan product"})
+		return
+	}
+
+	calendar, err := loadBusinessCalendar(tx)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load holiday calendar"})
+		return
+	}
+
+	previousVersion := loan.ScheduleVersion
+	version, err := startScheduleVersion(tx, &loan, "RESTRUCTURE", input.Reason)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule version"})
+		return
+	}
+
+	schedule := utils.BuildWeeklySchedule(utils.ScheduleTerms{
+		Principal:      outstandingPrincipal + capitalizedInterest,
+		AnnualRate:     newRate / 100,
+		DayCount:       product.DayCountConvention,
+		Periods:        newTenor,
+		GracePeriods:   input.GracePeriods,
+		StartDate:      now,
+		Calendar:       calendar,
+		DateAdjustment: dateAdjustment(product),
+	})
+
+	if err := createInstallments(tx, loan, schedule); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create installment"})
+		return
+	}
+
+	loan.UpdatedAt = now
+
+	if err := tx.Save(&loan).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update loan"})
+		return
+	}
+
+	restructure := models.LoanRestructure{
+		LoanID:               loan.ID,
+		PreviousVersion:      previousVersion,
+		NewVersion:           version.VersionNumber,
+		OutstandingPrincipal: outstandingPrincipal,
+		CapitalizedInterest:  capitalizedInterest,
+		AccruedInterest:      quote.AccruedInterest,
+		PreviousInterestRate: previousRate,
+		NewInterestRate:      newRate,
+		PreviousTenor:        previousTenor,
+		NewTenor:             newTenor,
+		GracePeriods:         input.GracePeriods,
+		Reason:               input.Reason,
+		ApprovedBy:           principal.UserID,
+		ApprovedAt:           now,
+		CreatedAt:            now,
+	}
+
+	if err := tx.Create(&restructure).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record restructuring"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Loan restructured successfully",
+		"restructure": restructure,
+	})
+}
+
+func GetLoanRestructures(c *gin.Context) {
+	var loan models.Loan
+	if err := config.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	var restructures []models.LoanRestructure
+	if err := config.DB.
+		Where("loan_id = ?", loan.ID).
+		Order("new_version asc").
+		Find(&restructures).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch restructures"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Restructures fetched successfully",
+		"restructures": restructures,
+	})
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TopUpLoan refinances an active loan into a new, larger one. The new loan's
+// disbursement first settles the old loan at its payoff amount and only the
+// rest is paid out to the borrower. The new loan is booked to the primary
+// borrower, so co-borrowers and guarantors cannot top up on their behalf.
+func TopUpLoan(c *gin.Context) {
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	var input struct {
+		LoanAmount  float64 `json:"loan_amount" binding:"required"`
+		LoanLength  int     `json:"loan_length" binding:"required"`
+		ProductCode string  `json:"product_code"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, tx, loan) {
+		tx.Rollback()
+		return
+	}
+
+	if userID, scoped := borrowerScope(c); scoped && userID != loan.UserID {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the primary borrower can top up this loan"})
+		return
+	}
+
+	riskGrade, err := resolveRiskGrade(tx, loan.UserID, loan.RiskGrade)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve risk grade"})
+		return
+	}
+
+	now := time.Now()
+
+	offer, quote, err := prepareTopUp(tx, loan, input.ProductCode, input.LoanAmount, input.LoanLength, riskGrade, now)
+	if err != nil {
+		tx.Rollback()
+		respondOfferError(c, err)
+		return
+	}
+
+	decision, err := decideApplication(tx, loan.UserID, offer, creditApplication{
+		LoanAmount:  input.LoanAmount,
+		LoanLength:  input.LoanLength,
+		ProductCode: input.ProductCode,
+		TopUpLoanID: loan.ID,
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate loan application"})
+		return
+	}
+
+	switch decision.Decision {
+	case utils.CreditDecisionReject:
+		tx.Commit()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Top-up application rejected", "decision": decision})
+		return
+	case utils.CreditDecisionManualReview:
+		tx.Commit()
+		c.JSON(http.StatusAccepted, gin.H{"message": "Top-up application referred for manual review", "decision": decision})
+		return
+	}
+
+	if decision.RiskGrade != "" {
+		decided, err := resolveRiskGrade(tx, loan.UserID, decision.RiskGrade)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve risk grade"})
+			return
+		}
+
+		if decided != riskGrade {
+			offer, quote, err = prepareTopUp(tx, loan, input.ProductCode, input.LoanAmount, input.LoanLength, decided, now)
+			if err != nil {
+				tx.Rollback()
+				respondOfferError(c, err)
+				return
+			}
+		}
+	}
+
+	newLoan, topUp, err := bookTopUp(tx, &loan, offer, quote, now)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to top up loan"})
+		return
+	}
+
+	decision.LoanID = &newLoan.ID
+	if err := tx.Save(&decision).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record credit decision"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Loan topped up successfully",
+		"top_up":     topUp,
+		"loan":       newLoan,
+		"payoff":     quote,
+		"disclosure": offer.Disclosure,
+		"decision":   decision,
+	})
+}
+
+// prepareTopUp checks that loan can be topped up to loanAmount and prices the
+// new loan at riskGrade. An empty productCode keeps the loan's own product.
+func prepareTopUp(tx *gorm.DB, loan models.Loan, productCode string, loanAmount float64, loanLength int, riskGrade string, now time.Time) (loanOffer, payoffQuote, error) {
+	if loan.LoanStatus != "ACTIVE" {
+		return loanOffer{}, payoffQuote{}, &offerError{http.StatusBadRequest, "Only active loans can be topped up"}
+	}
+
+	if loan.CreditLineID != nil {
+		return loanOffer{}, payoffQuote{}, &offerError{http.StatusBadRequest, "Loans converted from a credit line cannot be topped up"}
+	}
+
+	inArrears, err := loanInArrears(tx, loan, now)
+	if err != nil {
+		return loanOffer{}, payoffQuote{}, err
+	}
+	if inArrears {
+		return loanOffer{}, payoffQuote{}, &offerError{http.StatusBadRequest, "Loan has overdue installments, cannot top up"}
+	}
+
+	if productCode == "" {
+		product, err := loanProduct(tx, loan)
+		if err != nil {
+			return loanOffer{}, payoffQuote{}, err
+		}
+		productCode = product.ProductCode
+	}
+
+	quote, err := loanPayoff(tx, loan, now)
+	if err != nil {
+		return loanOffer{}, payoffQuote{}, err
+	}
+
+	if loanAmount <= quote.PayoffAmount {
+		return loanOffer{}, payoffQuote{}, &offerError{http.StatusBadRequest, fmt.Sprintf("Top-up amount must be greater than the payoff amount: %.2f", quote.PayoffAmount)}
+	}
+
+	offer, err := prepareLoanOffer(tx, productCode, loanAmount, loanLength, now, riskGrade)
+	if err != nil {
+		return loanOffer{}, payoffQuote{}, err
+	}
+
+	if err := checkLoanEligibility(tx, loan.UserID, offer, loan.ID); err != nil {
+		return loanOffer{}, payoffQuote{}, err
+	}
+
+	return offer, quote, nil
+}
+
+// bookTopUp creates the new loan from offer, carrying over the old loan's
+// co-borrowers and guarantors, and settles the old loan at quote.
+func bookTopUp(tx *gorm.DB, loan *models.Loan, offer loanOffer, quote payoffQuote, now time.Time) (models.Loan, models.LoanTopUp, error) {
+	var carriedParties []models.LoanParty
+	if err := tx.Where("loan_id = ? AND party_role <> ?", loan.ID, "PRIMARY").Find(&carriedParties).Error; err != nil {
+		return models.Loan{}, models.LoanTopUp{}, err
+	}
+	parties := make([]loanPartyInput, 0, len(carriedParties))
+	for _, party := range carriedParties {
+		parties = append(parties, loanPartyInput{UserID: party.UserID, PartyRole: party.PartyRole})
+	}
+
+	newLoan := models.Loan{UserID: loan.UserID, RefinancedFrom: &loan.ID}
+	if err := createLoanFromOffer(tx, &newLoan, offer, parties); err != nil {
+		return models.Loan{}, models.LoanTopUp{}, err
+	}
+
+	payment, err := settleLoanPayoff(tx, loan, quote, newLoan.LoanCode)
+	if err != nil {
+		return models.Loan{}, models.LoanTopUp{}, err
+	}
+
+	topUp := models.LoanTopUp{
+		PreviousLoanID:       loan.ID,
+		NewLoanID:            newLoan.ID,
+		SettlementPaymentID:  payment.ID,
+		OutstandingPrincipal: quote.OutstandingPrincipal,
+		InterestSettled:      utils.RoundFloat(quote.InterestDue+quote.AccruedInterest, 2),
+		PayoffAmount:         quote.PayoffAmount,
+		NewLoanAmount:        newLoan.LoanAmount,
+		NetDisbursement:      utils.RoundFloat(newLoan.LoanAmount-quote.PayoffAmount, 2),
+		CreatedAt:            now,
+	}
+
+	if err := tx.Create(&topUp).Error; err != nil {
+		return models.Loan{}, models.LoanTopUp{}, err
+	}
+
+	return newLoan, topUp, nil
+}
+
+func GetLoanTopUps(c *gin.Context) {
+	var loan models.Loan
+	if err := config.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	var topUps []models.LoanTopUp
+	if err := config.DB.
+		Where("previous_loan_id = ? OR new_loan_id = ?", loan.ID, loan.ID).
+		Order("created_at asc").
+		Find(&topUps).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch top-ups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Top-ups fetched successfully",
+		"top_ups": topUps,
+	})
+}
+
+// loanInArrears reports whether any pending installment is already past its
+// effective due date.
+func loanInArrears(tx *gorm.DB, loan models.Loan, asOf time.Time) (bool, error) {
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND paid_status = ? AND due_date < ?", loan.ID, "PENDING", asOf).
+		Find(&installments).Error; err != nil {
+		return false, err
+	}
+
+	calendar, err := loadBusinessCalendar(tx)
+	if err != nil {
+		return false, err
+	}
+
+	adjustment, err := loanDateAdjustment(tx, loan)
+	if err != nil {
+		return false, err
+	}
+
+	for _, inst := range installments {
+		if effectiveDueDate(calendar, inst.DueDate, adjustment).Before(asOf) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// settleLoanPayoff closes the loan as paid by quote. Principal and the
+// interest counted in the quote are marked paid; interest for periods not yet
+// started is waived. The pending installments end as REFINANCED and a
+// REFINANCE payment records the settlement against the old loan.
+func settleLoanPayoff(tx *gorm.DB, loan *models.Loan, quote payoffQuote, reference string) (models.Payment, error) {
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Order("sequence asc").
+		Find(&installments).Error; err != nil {
+		return models.Payment{}, err
+	}
+
+	now := time.Now()
+	accrued := quote.AccruedInterest
+	for i := range installments {
+		inst := &installments[i]
+
+		inst.PaidAmountPrincipal = inst.PrincipalAmount
+		if !inst.DueDate.After(quote.AsOf) {
+			inst.PaidAmountInterest = inst.InterestAmount
+		} else {
+			inst.PaidAmountInterest = utils.RoundFloat(inst.PaidAmountInterest+accrued, 2)
+			accrued = 0
+		}
+		inst.PaidAmountInstallment = utils.RoundFloat(inst.PaidAmountPrincipal+inst.PaidAmountInterest, 2)
+		inst.PaidStatus = "REFINANCED"
+		inst.UpdatedAt = now
+
+		if err := tx.Save(inst).Error; err != nil {
+			return models.Payment{}, err
+		}
+	}
+
+	payment := models.Payment{
+		UserID:            loan.UserID,
+		LoanID:            loan.ID,
+		PaymentCode:       utils.GeneratePaymentCode(),
+		PaymentAmount:     quote.PayoffAmount,
+		PaymentChannel:    "REFINANCE",
+		ExternalReference: &reference,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := tx.Create(&payment).Error; err != nil {
+		return models.Payment{}, err
+	}
+
+	loan.LoanStatus = "CLOSED"
+	loan.UpdatedAt = now
+	if err := tx.Save(loan).Error; err != nil {
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
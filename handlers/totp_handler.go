@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+// totpClock is the time the TOTP flows check codes against. Tests replace it
+// to pin codes to a known step.
+var totpClock = time.Now
+
+func EnrollTOTP(c *gin.Context) {
+	userIDFloat, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := uint64(userIDFloat.(float64))
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+
+	if err := config.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Two-factor enrollment started",
+		"secret":           secret,
+		"provisioning_uri": utils.TOTPProvisioningURI(totpIssuer(), user.EmailAddress, secret),
+	})
+}
+
+func ConfirmTOTP(c *gin.Context) {
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDFloat, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := uint64(userIDFloat.(float64))
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor enrollment has not been started"})
+		return
+	}
+
+	now := totpClock()
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, input.Code, now, user.TOTPLastStep)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	user.UpdatedAt = now
+
+	if err := tx.Save(&user).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	if err := replaceRecoveryCodes(tx, user.ID, codes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recovery codes"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+func VerifyLoginTOTP(c *gin.Context) {
+	var input struct {
+		MFAToken     string `json:"mfa_token" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Code == "" && input.RecoveryCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either code or recovery_code is required"})
+		return
+	}
+
+	claims, err := utils.ValidateJWT(input.MFAToken)
+	if err != nil || claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	userIDFloat, ok := claims["mfa_user_id"].(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, uint64(userIDFloat)).Error; err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	now := totpClock()
+	attemptKey := fmt.Sprintf("totp:%d", user.ID)
+
+	record, err := config.LoginAttempts.Get(attemptKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return
+	}
+	if retryAfter := utils.LoginRetryAfter(record, utils.AccountLoginPolicy, now); retryAfter > 0 {
+		tooManyLoginAttempts(c, retryAfter)
+		return
+	}
+
+	var verified bool
+	if input.Code != "" {
+		verified, err = consumeTOTPCode(&user, input.Code, now)
+	} else {
+		verified, err = consumeRecoveryCode(user.ID, input.RecoveryCode, now)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify authentication code"})
+		return
+	}
+
+	if !verified {
+		if err := recordLoginFailure("TOTP", attemptKey, utils.AccountLoginPolicy, user.EmailAddress, c.ClientIP(), now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record login attempt"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	if err := config.LoginAttempts.Reset(attemptKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset login attempts"})
+		return
+	}
+
+	respondLoginSuccess(c, user)
+}
+
+func consumeTOTPCode(user *models.User, code string, now time.Time) (bool, error) {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, now, user.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Updates(map[string]interface{}{"totp_last_step": step, "updated_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	user.TOTPLastStep = step
+	return result.RowsAffected == 1, nil
+}
+
+func consumeRecoveryCode(userID uint64, code string, now time.Time) (bool, error) {
+	codeHash := utils.HashToken(utils.NormalizeRecoveryCode(code))
+
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint64, codes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		recoveryCode := models.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  utils.HashToken(code),
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&recoveryCode).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func totpIssuer() string {
+	if issuer, exists := os.LookupEnv("TOTP_ISSUER"); exists {
+		return issuer
+	}
+	return "go-billing-engine"
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// stubTOTP pins totpClock to now and gives the test its own attempt store.
+func stubTOTP(t *testing.T, now time.Time) {
+	t.Helper()
+
+	clock, attempts := totpClock, config.LoginAttempts
+	totpClock = func() time.Time { return now }
+	config.LoginAttempts = utils.NewMemoryLoginAttemptStore()
+	t.Cleanup(func() {
+		totpClock, config.LoginAttempts = clock, attempts
+	})
+}
+
+func totpCode(t *testing.T, step int64) string {
+	t.Helper()
+
+	code, err := utils.TOTPCode(testTOTPSecret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func totpUserRows(enabled bool, lastStep int64) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "email_address", "totp_secret", "totp_enabled", "totp_last_step"}).
+		AddRow(5, "borrower@example.com", testTOTPSecret, enabled, lastStep)
+}
+
+// The step used throughout: codes are checked at its first and last second.
+const totpTestStep = int64(56666667)
+
+var (
+	totpStepStart = time.Unix(totpTestStep*utils.TOTPPeriod, 0)
+	totpStepEnd   = time.Unix((totpTestStep+1)*utils.TOTPPeriod-1, 0)
+)
+
+func TestConfirmTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		codeStep int64
+		lastStep int64
+		want     int
+	}{
+		{name: "current step", now: totpStepStart, codeStep: totpTestStep, want: http.StatusOK},
+		{name: "previous step at the start of the window", now: totpStepStart, codeStep: totpTestStep - 1, want: http.StatusOK},
+		{name: "two steps back is outside the window", now: totpStepStart, codeStep: totpTestStep - 2, want: http.StatusBadRequest},
+		{name: "next step at the end of the window", now: totpStepEnd, codeStep: totpTestStep + 1, want: http.StatusOK},
+		{name: "two steps ahead is outside the window", now: totpStepEnd, codeStep: totpTestStep + 2, want: http.StatusBadRequest},
+		{name: "step already used", now: totpStepStart, codeStep: totpTestStep, lastStep: totpTestStep, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			stubTOTP(t, tt.now)
+
+			mock.ExpectQuery(`FROM "users"`).WillReturnRows(totpUserRows(false, tt.lastStep))
+			if tt.want == http.StatusOK {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "users"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM "recovery_codes"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				for i := 0; i < recoveryCodeCount; i++ {
+					mock.ExpectQuery(`INSERT INTO "recovery_codes"`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+				}
+				mock.ExpectCommit()
+			}
+
+			body := fmt.Sprintf(`{"code":%q}`, totpCode(t, tt.codeStep))
+			w := serve(http.MethodPost, "/confirm", "/confirm", body, nil,
+				func(c *gin.Context) { c.Set("user_id", float64(5)) }, ConfirmTOTP)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestVerifyLoginTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		codeStep int64
+		lastStep int64
+		// consumes is set when the code is valid and the handler tries to
+		// advance totp_last_step; rowsAffected is what that conditional
+		// update reports, and zero means a concurrent login got there first.
+		consumes     bool
+		rowsAffected int64
+		locked       bool
+		want         int
+	}{
+		{name: "current step", now: totpStepStart, codeStep: totpTestStep, consumes: true, rowsAffected: 1, want: http.StatusOK},
+		{name: "previous step at the start of the window", now: totpStepStart, codeStep: totpTestStep - 1, consumes: true, rowsAffected: 1, want: http.StatusOK},
+		{name: "next step at the end of the window", now: totpStepEnd, codeStep: totpTestStep + 1, consumes: true, rowsAffected: 1, want: http.StatusOK},
+		{name: "outside the window", now: totpStepEnd, codeStep: totpTestStep + 2, want: http.StatusUnauthorized},
+		{name: "replay of the last used step", now: totpStepStart, codeStep: totpTestStep, lastStep: totpTestStep, want: http.StatusUnauthorized},
+		{name: "newer step than the last used one", now: totpStepStart, codeStep: totpTestStep, lastStep: totpTestStep - 1, consumes: true, rowsAffected: 1, want: http.StatusOK},
+		{name: "step consumed by a concurrent login", now: totpStepStart, codeStep: totpTestStep, consumes: true, want: http.StatusUnauthorized},
+		{name: "locked out", now: totpStepStart, codeStep: totpTestStep, locked: true, want: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			stubTOTP(t, tt.now)
+
+			if tt.locked {
+				if err := config.LoginAttempts.Lock("totp:5", tt.now.Add(time.Minute)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			mock.ExpectQuery(`FROM "users"`).WillReturnRows(totpUserRows(true, tt.lastStep))
+			if tt.consumes {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "users" SET "totp_last_step"=\$1,"updated_at"=\$2 WHERE id = \$3 AND totp_last_step < \$4`).
+					WithArgs(tt.codeStep, tt.now, 5, tt.codeStep).
+					WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+				mock.ExpectCommit()
+			}
+
+			token, err := utils.GenerateMFAToken(5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body := fmt.Sprintf(`{"mfa_token":%q,"code":%q}`, token, totpCode(t, tt.codeStep))
+			w := serve(http.MethodPost, "/verify", "/verify", body, nil, VerifyLoginTOTP)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func AssignVirtualAccount(c *gin.Context) {
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	var input struct {
+		PartnerCode string `json:"partner_code" binding:"required"`
+		PerBorrower bool   `json:"per_borrower"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var loan models.Loan
+	if err := config.DB.First(&loan, loanID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	var partner models.PaymentPartner
+	if err := config.DB.Where("partner_code = ? AND partner_status = ?", strings.ToUpper(input.PartnerCode), "ACTIVE").First(&partner).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment partner not found"})
+		return
+	}
+
+	if partner.VAPrefix == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment partner does not issue virtual accounts"})
+		return
+	}
+
+	query := config.DB.Where("partner_code = ? AND user_id = ? AND va_status = ?", partner.PartnerCode, loan.UserID, "ACTIVE")
+	if input.PerBorrower {
+		query = query.Where("loan_id IS NULL")
+	} else {
+		query = query.Where("loan_id = ?", loan.ID)
+	}
+
+	var existingVA models.VirtualAccount
+	if err := query.First(&existingVA).Error; err == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message":         "Virtual account already assigned",
+			"virtual_account": existingVA,
+		})
+		return
+	}
+
+	virtualAccount := models.VirtualAccount{
+		UserID:      loan.UserID,
+		PartnerCode: partner.PartnerCode,
+		VAStatus:    "ACTIVE",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if input.PerBorrower {
+		virtualAccount.VANumber = utils.GenerateVANumber(partner.VAPrefix, false, loan.UserID)
+	} else {
+		virtualAccount.LoanID = &loan.ID
+		virtualAccount.VANumber = utils.GenerateVANumber(partner.VAPrefix, true, loan.ID)
+	}
+
+	if err := config.DB.Create(&virtualAccount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign virtual account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Virtual account assigned successfully",
+		"virtual_account": virtualAccount,
+	})
+}
+
+func GetLoanVirtualAccounts(c *gin.Context) {
+	loanID := c.Param("id")
+
+	var loan models.Loan
+	if err := config.DB.First(&loan, loanID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if !ensureLoanAccess(c, config.DB, loan) {
+		return
+	}
+
+	var virtualAccounts []models.VirtualAccount
+	if err := config.DB.
+		Where("loan_id = ? OR (loan_id IS NULL AND user_id = ?)", loan.ID, loan.UserID).
+		Order("created_at asc").
+		Find(&virtualAccounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch virtual accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Virtual accounts fetched successfully",
+		"virtual_accounts": virtualAccounts,
+	})
+}
+
+func VirtualAccountCallback(c *gin.Context) {
+	partner := c.MustGet("payment_partner").(models.PaymentPartner)
+
+	var input struct {
+		VANumber      string    `json:"va_number" binding:"required"`
+		Amount        float64   `json:"amount" binding:"required,gt=0"`
+		TransactionID string    `json:"transaction_id" binding:"required"`
+		PaidAt        time.Time `json:"paid_at"`
+	}
+
+	if err := c.ShouldBindBodyWith(&input, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	paidAt := input.PaidAt
+	if paidAt.IsZero() {
+		paidAt = now
+	}
+
+	notification := models.VANotification{
+		PartnerCode:   partner.PartnerCode,
+		TransactionID: input.TransactionID,
+		VANumber:      input.VANumber,
+		Amount:        input.Amount,
+		PaidAt:        paidAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if raw, ok := c.Get(gin.BodyBytesKey); ok {
+		notification.RawPayload = string(raw.([]byte))
+	}
+
+	tx := config.DB.Begin()
+
+	if err := processVANotification(tx, &notification); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process notification"})
+		return
+	}
+
+	if err := tx.Create(&notification).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record notification"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Notification received",
+		"process_status": notification.ProcessStatus,
+	})
+}
+
+// processVANotification matches a notification to a loan and posts it. The
+// bank has already moved the money, so anything that cannot be posted is
+// credited to the borrower or parked in the suspense queue instead of being
+// rejected. Only database failures are returned as errors.
+func processVANotification(tx *gorm.DB, notification *models.VANotification) error {
+	var virtualAccount models.VirtualAccount
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("va_number = ? AND partner_code = ?", notification.VANumber, notification.PartnerCode).
+		First(&virtualAccount).Error
+	if err != nil {
+		suspendVANotification(notification, "SUSPENSE", "Unknown virtual account")
+		return nil
+	}
+
+	if virtualAccount.VAStatus != "ACTIVE" {
+		suspendVANotification(notification, "SUSPENSE", "Virtual account is not active")
+		return nil
+	}
+
+	var previousCount int64
+	if err := tx.Model(&models.VANotification{}).
+		Where("partner_code = ? AND transaction_id = ?", notification.PartnerCode, notification.TransactionID).
+		Count(&previousCount).Error; err != nil {
+		return err
+	}
+	if previousCount > 0 {
+		suspendVANotification(notification, "DUPLICATE", "Duplicate notification")
+		return nil
+	}
+
+	var loan models.Loan
+	loanQuery := tx.Where("loan_status = ?", "ACTIVE")
+	if virtualAccount.LoanID != nil {
+		loanQuery = loanQuery.Where("id = ?", *virtualAccount.LoanID)
+	} else {
+		loanQuery = loanQuery.Where("user_id = ?", virtualAccount.UserID).Order("created_at asc")
+	}
+	if err := loanQuery.First(&loan).Error; err != nil {
+		if virtualAccount.LoanID != nil {
+			suspendVANotification(notification, "SUSPENSE", "No active loan for virtual account")
+			return nil
+		}
+
+		// A borrower VA with nothing to pay is still the borrower's money.
+		if _, err := adjustCreditBalance(tx, creditEntry{
+			UserID:          virtualAccount.UserID,
+			TransactionType: "UNMATCHED_FUNDS",
+			Amount:          notification.Amount,
+			Note:            "Virtual account " + notification.VANumber + " transaction " + notification.TransactionID,
+		}); err != nil {
+			return err
+		}
+		notification.ProcessStatus = "CREDITED"
+		return nil
+	}
+	notification.LoanID = &loan.ID
+
+	if err := tx.SavePoint("va_posting").Error; err != nil {
+		return err
+	}
+
+	payment, err := postPayment(tx, paymentPosting{
+		LoanID:            loan.ID,
+		PaymentAmount:     notification.Amount,
+		PaymentChannel:    "VIRTUAL_ACCOUNT",
+		PartnerCode:       notification.PartnerCode,
+		ExternalReference: notification.TransactionID,
+	})
+	if err != nil {
+		if rbErr := tx.RollbackTo("va_posting").Error; rbErr != nil {
+			return rbErr
+		}
+		suspendVANotification(notification, "SUSPENSE", err.Error())
+		return nil
+	}
+
+	notification.ProcessStatus = "POSTED"
+	notification.PaymentID = &payment.ID
+	return nil
+}
+
+func suspendVANotification(notification *models.VANotification, status, reason string) {
+	notification.ProcessStatus = status
+	notification.SuspenseReason = reason
+}
+
+func GetSuspenseNotifications(c *gin.Context) {
+	var notifications []models.VANotification
+	if err := config.DB.
+		Where("process_status IN ? AND resolved_at IS NULL", []string{"SUSPENSE", "DUPLICATE"}).
+		Order("created_at asc").
+		Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suspense queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Suspense queue fetched successfully",
+		"notifications": notifications,
+	})
+}
+
+func ResolveSuspenseNotification(c *gin.Context) {
+	notificationID := c.Param("id")
+
+	var input struct {
+		Action string `json:"action" binding:"required,oneof=POST CREDIT DISMISS"`
+		LoanID uint64 `json:"loan_id"`
+		UserID uint64 `json:"user_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Action == "POST" && input.LoanID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "loan_id is required to post a notification"})
+		return
+	}
+
+	if input.Action == "CREDIT" && input.UserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required to credit a notification"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var notification models.VANotification
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&notification, notificationID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	if notification.ResolvedAt != nil || notification.ProcessStatus == "POSTED" || notification.ProcessStatus == "CREDITED" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification is not in the suspense queue"})
+		return
+	}
+
+	now := time.Now()
+
+	switch input.Action {
+	case "POST":
+		payment, err := postPayment(tx, paymentPosting{
+			LoanID:            input.LoanID,
+			PaymentAmount:     notification.Amount,
+			PaymentChannel:    "VIRTUAL_ACCOUNT",
+			PartnerCode:       notification.PartnerCode,
+			ExternalReference: notification.TransactionID,
+		})
+		if err != nil {
+			tx.Rollback()
+			respondPostingError(c, err)
+			return
+		}
+
+		notification.ProcessStatus = "POSTED"
+		notification.LoanID = &input.LoanID
+		notification.PaymentID = &payment.ID
+	case "CREDIT":
+		if _, err := adjustCreditBalance(tx, creditEntry{
+			UserID:          input.UserID,
+			TransactionType: "UNMATCHED_FUNDS",
+			Amount:          notification.Amount,
+			Note:            "Virtual account " + notification.VANumber + " transaction " + notification.TransactionID,
+		}); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to credit borrower balance"})
+			return
+		}
+
+		notification.ProcessStatus = "CREDITED"
+	default:
+		notification.ProcessStatus = "DISMISSED"
+	}
+
+	notification.ResolvedAt = &now
+	notification.UpdatedAt = now
+
+	if err := tx.Save(&notification).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve notification"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Notification resolved successfully",
+		"notification": notification,
+	})
+}
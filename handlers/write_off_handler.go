@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// WriteOffLoan moves an active loan to WRITTEN_OFF and freezes its pending
+// installments. The admin fee financed into the loan is reported separately
+// from principal, in proportion to how much of the financed total is unpaid.
+// Only interest already due is written off; future interest was never earned.
+func WriteOffLoan(c *gin.Context) {
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if loan.LoanStatus != "ACTIVE" {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only active loans can be written off"})
+		return
+	}
+
+	var installments []models.Installment
+	if err := tx.
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Find(&installments).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load installments"})
+		return
+	}
+
+	now := time.Now()
+	var outstandingPrincipal, dueInterest float64
+	for _, inst := range installments {
+		outstandingPrincipal += inst.PrincipalAmount - inst.PaidAmountPrincipal
+		if !inst.DueDate.After(now) {
+			dueInterest += inst.InterestAmount - inst.PaidAmountInterest
+		}
+	}
+
+	var fees float64
+	if loan.NTFTotal > 0 {
+		fees = outstandingPrincipal * loan.AdminTotal / loan.NTFTotal
+	}
+
+	writeOff := models.LoanWriteOff{
+		LoanID:              loan.ID,
+		ScheduleVersion:     loan.ScheduleVersion,
+		WrittenOffPrincipal: utils.RoundFloat(outstandingPrincipal-fees, 2),
+		WrittenOffInterest:  utils.RoundFloat(dueInterest, 2),
+		WrittenOffFees:      utils.RoundFloat(fees, 2),
+		Reason:              input.Reason,
+		ApprovedBy:          principal.UserID,
+		CreatedAt:           now,
+	}
+
+	if err := tx.Model(&models.Installment{}).
+		Where("loan_id = ? AND paid_status = ?", loan.ID, "PENDING").
+		Updates(map[string]interface{}{"paid_status": "WRITTEN_OFF", "updated_at": now}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to freeze installments"})
+		return
+	}
+
+	loan.LoanStatus = "WRITTEN_OFF"
+	loan.UpdatedAt = now
+
+	if err := tx.Save(&loan).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update loan"})
+		return
+	}
+
+	if err := tx.Create(&writeOff).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record write-off"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Loan written off successfully",
+		"write_off": writeOff,
+	})
+}
+
+// RecordLoanRecovery books money received on a written-off loan. Recoveries
+// never reopen installments; they are tracked against the write-off total.
+func RecordLoanRecovery(c *gin.Context) {
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	var input struct {
+		Amount     float64   `json:"amount" binding:"required,gt=0"`
+		Reference  string    `json:"reference"`
+		Note       string    `json:"note"`
+		ReceivedAt time.Time `json:"received_at"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok || !principal.IsUser() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	var writeOff models.LoanWriteOff
+	if err := tx.Where("loan_id = ?", loan.ID).First(&writeOff).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Loan has not been written off"})
+		return
+	}
+
+	var recovered float64
+	if err := tx.Model(&models.LoanRecovery{}).
+		Where("loan_id = ?", loan.ID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&recovered).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load recoveries"})
+		return
+	}
+
+	writtenOffTotal := writeOff.WrittenOffPrincipal + writeOff.WrittenOffInterest + writeOff.WrittenOffFees
+	if utils.RoundFloat(recovered+input.Amount, 2) > utils.RoundFloat(writtenOffTotal, 2) {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Recovery exceeds the remaining written-off amount: %.2f", writtenOffTotal-recovered)})
+		return
+	}
+
+	now := time.Now()
+	receivedAt := input.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = now
+	}
+
+	recovery := models.LoanRecovery{
+		LoanID:       loan.ID,
+		UserID:       loan.UserID,
+		RecoveryCode: utils.GenerateRecoveryCode(),
+		Amount:       input.Amount,
+		Reference:    input.Reference,
+		Note:         input.Note,
+		RecordedBy:   principal.UserID,
+		ReceivedAt:   receivedAt,
+		CreatedAt:    now,
+	}
+
+	if err := tx.Create(&recovery).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record recovery"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Recovery recorded successfully",
+		"recovery": recovery,
+	})
+}
+
+func GetLoanWriteOff(c *gin.Context) {
+	loanID := c.Param("id")
+
+	var writeOff models.LoanWriteOff
+	if err := config.DB.Where("loan_id = ?", loanID).First(&writeOff).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Write-off not found"})
+		return
+	}
+
+	var recoveries []models.LoanRecovery
+	if err := config.DB.
+		Where("loan_id = ?", loanID).
+		Order("received_at asc").
+		Find(&recoveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recoveries"})
+		return
+	}
+
+	var recovered float64
+	for _, recovery := range recoveries {
+		recovered += recovery.Amount
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Write-off fetched successfully",
+		"write_off":       writeOff,
+		"recoveries":      recoveries,
+		"total_recovered": utils.RoundFloat(recovered, 2),
+	})
+}
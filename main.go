@@ -1,7 +1,10 @@
 package main
 
 import (
+	"log"
+
 	"go-billing-engine/config"
+	"go-billing-engine/handlers"
 	"go-billing-engine/routes"
 
 	"github.com/gin-gonic/gin"
@@ -10,7 +13,16 @@ import (
 func main() {
 	config.ConnectDatabase()
 
+	if err := handlers.LoadCreditRulesFile(config.CreditRulesFile()); err != nil {
+		log.Fatal("Failed to load CREDIT_RULES_FILE:", err)
+	}
+
+	handlers.StartCollectionScheduler(config.CollectionSchedulerInterval())
+
 	r := gin.Default()
+	if err := r.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 
 	routes.SetupRoutes(r)
 
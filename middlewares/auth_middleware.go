@@ -1,15 +1,24 @@
 package middlewares
 
 import (
+	"crypto/subtle"
+	"go-billing-engine/config"
+	"go-billing-engine/models"
 	"go-billing-engine/utils"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticateAPIKey(c, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
@@ -32,7 +41,106 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		var user models.User
+		if err := config.DB.Select("id", "role").First(&user, uint64(userID)).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", userID)
+		c.Set("principal", utils.Principal{
+			Type:   utils.PrincipalTypeUser,
+			UserID: user.ID,
+			Role:   user.Role,
+		})
 		c.Next()
 	}
 }
+
+func authenticateAPIKey(c *gin.Context, apiKey string) {
+	prefix, err := utils.ParseAPIKeyPrefix(apiKey)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	var client models.APIClient
+	if err := config.DB.Where("key_prefix = ?", prefix).First(&client).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(apiKey)), []byte(client.KeyHash)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	if client.ClientStatus != "ACTIVE" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "API key has been revoked"})
+		c.Abort()
+		return
+	}
+
+	// ClientIP only honours X-Forwarded-For from TRUSTED_PROXIES, so a caller
+	// cannot claim an allowed address by sending the header itself.
+	if !utils.IPAllowed(c.ClientIP(), utils.SplitList(client.AllowedIPs)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Request IP is not allowed for this API key"})
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&models.APIClient{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", client.ID, now.Add(-time.Minute)).
+		Update("last_used_at", now).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record API key usage"})
+		c.Abort()
+		return
+	}
+
+	c.Set("principal", utils.Principal{
+		Type:     utils.PrincipalTypeAPIClient,
+		ClientID: client.ID,
+		Scopes:   utils.SplitList(client.Scopes),
+	})
+	c.Next()
+}
+
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("principal")
+		principal, ok := value.(utils.Principal)
+		if !ok || !principal.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Missing required scope: " + scope})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("principal")
+		principal, ok := value.(utils.Principal)
+		if !ok || !principal.IsUser() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if principal.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"bytes"
+	"go-billing-engine/config"
+	"go-billing-engine/models"
+	"go-billing-engine/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+const signatureReplayWindow = 5 * time.Minute
+
+// SignatureMiddleware authenticates partner callbacks signed with
+// utils.SignRequest. Requests outside the replay window, or whose signature
+// has been seen before, are rejected.
+func SignatureMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		partnerCode := c.GetHeader("X-Partner-Code")
+		timestamp := c.GetHeader("X-Timestamp")
+		signature := c.GetHeader("X-Signature")
+
+		if partnerCode == "" || timestamp == "" || signature == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Signature headers missing"})
+			c.Abort()
+			return
+		}
+
+		unixTime, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid timestamp"})
+			c.Abort()
+			return
+		}
+
+		now := time.Now()
+		skew := now.Sub(time.Unix(unixTime, 0))
+		if skew > signatureReplayWindow || skew < -signatureReplayWindow {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Request timestamp outside the allowed window"})
+			c.Abort()
+			return
+		}
+
+		var partner models.PaymentPartner
+		if err := config.DB.Where("partner_code = ? AND partner_status = ?", partnerCode, "ACTIVE").First(&partner).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unknown partner"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !utils.VerifyRequestSignature(partner.SigningSecret, c.Request.Method, c.Request.URL.Path, timestamp, body, signature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+			c.Abort()
+			return
+		}
+
+		seen := models.SignedRequest{
+			PartnerCode: partner.PartnerCode,
+			Signature:   strings.ToLower(signature),
+			CreatedAt:   now,
+		}
+		result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&seen)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record request signature"})
+			c.Abort()
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Request has already been processed"})
+			c.Abort()
+			return
+		}
+
+		config.DB.Where("created_at < ?", now.Add(-2*signatureReplayWindow)).Delete(&models.SignedRequest{})
+
+		c.Set("payment_partner", partner)
+		c.Next()
+	}
+}
@@ -0,0 +1,126 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-billing-engine/config"
+	"go-billing-engine/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func mockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	return mock
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	const (
+		secret = "partner-secret"
+		path   = "/callbacks/payments"
+		body   = `{"loan_code":"LOA-20240101-001","amount":125000}`
+	)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		signedAs  string
+		sentBody  string
+		omit      bool
+		// recorded is how many rows the replay guard insert reports; zero
+		// means the signature was seen before.
+		recorded int
+		want     int
+	}{
+		{name: "valid", timestamp: now, sentBody: body, recorded: 1, want: http.StatusOK},
+		{name: "valid near the edge of the window", timestamp: now.Add(-signatureReplayWindow + 10*time.Second), sentBody: body, recorded: 1, want: http.StatusOK},
+		{name: "tampered body", timestamp: now, sentBody: strings.Replace(body, "125000", "925000", 1), want: http.StatusUnauthorized},
+		{name: "wrong secret", timestamp: now, signedAs: "other-secret", sentBody: body, want: http.StatusUnauthorized},
+		{name: "stale timestamp", timestamp: now.Add(-signatureReplayWindow - time.Minute), sentBody: body, want: http.StatusUnauthorized},
+		{name: "timestamp in the future", timestamp: now.Add(signatureReplayWindow + time.Minute), sentBody: body, want: http.StatusUnauthorized},
+		{name: "replayed signature", timestamp: now, sentBody: body, recorded: 0, want: http.StatusConflict},
+		{name: "missing headers", omit: true, sentBody: body, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+
+			stale := tt.timestamp.Sub(now) > signatureReplayWindow || now.Sub(tt.timestamp) > signatureReplayWindow
+			if !tt.omit && !stale {
+				mock.ExpectQuery(`FROM "payment_partners"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "partner_code", "signing_secret", "partner_status"}).
+						AddRow(1, "BANKX", secret, "ACTIVE"))
+			}
+			if tt.want == http.StatusOK || tt.want == http.StatusConflict {
+				rows := sqlmock.NewRows([]string{"id"})
+				if tt.recorded == 1 {
+					rows.AddRow(1)
+				}
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO "signed_requests" .* ON CONFLICT DO NOTHING`).WillReturnRows(rows)
+				mock.ExpectCommit()
+			}
+			if tt.want == http.StatusOK {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM "signed_requests"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			}
+
+			signedAs := secret
+			if tt.signedAs != "" {
+				signedAs = tt.signedAs
+			}
+			timestamp := strconv.FormatInt(tt.timestamp.Unix(), 10)
+
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tt.sentBody))
+			if !tt.omit {
+				req.Header.Set("X-Partner-Code", "BANKX")
+				req.Header.Set("X-Timestamp", timestamp)
+				req.Header.Set("X-Signature", utils.SignRequest(signedAs, http.MethodPost, path, timestamp, []byte(body)))
+			}
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST(path, SignatureMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
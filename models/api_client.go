@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type APIClient struct {
+	ID           uint64     `gorm:"primaryKey;column:id" json:"id"`
+	ClientName   string     `gorm:"column:client_name;type:varchar(255);not null" json:"client_name"`
+	KeyPrefix    string     `gorm:"column:key_prefix;type:varchar(50);uniqueIndex;not null" json:"key_prefix"`
+	KeyHash      string     `gorm:"column:key_hash;type:varchar(255);not null" json:"-"`
+	Scopes       string     `gorm:"column:scopes;type:text;not null" json:"scopes"`
+	AllowedIPs   string     `gorm:"column:allowed_ips;type:text" json:"allowed_ips"`
+	ClientStatus string     `gorm:"column:client_status;type:varchar(50);default:ACTIVE;not null" json:"client_status"`
+	LastUsedAt   *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type BankStatement struct {
+	ID            uint64    `gorm:"primaryKey;column:id" json:"id"`
+	AccountNumber string    `gorm:"column:account_number;type:varchar(100)" json:"account_number"`
+	FileName      string    `gorm:"column:file_name;type:varchar(255);not null" json:"file_name"`
+	FileFormat    string    `gorm:"column:file_format;type:varchar(20);not null" json:"file_format"`
+	FileHash      string    `gorm:"column:file_hash;type:varchar(255);uniqueIndex;not null" json:"-"`
+	PeriodStart   time.Time `gorm:"column:period_start" json:"period_start"`
+	PeriodEnd     time.Time `gorm:"column:period_end" json:"period_end"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type BankStatementLine struct {
+	ID          uint64    `gorm:"primaryKey;column:id" json:"id"`
+	StatementID uint64    `gorm:"column:statement_id;index;not null" json:"statement_id"`
+	LineNumber  int       `gorm:"column:line_number;not null" json:"line_number"`
+	ValueDate   time.Time `gorm:"column:value_date;not null" json:"value_date"`
+	Direction   string    `gorm:"column:direction;type:varchar(10);not null" json:"direction"`
+	Amount      float64   `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	Reference   string    `gorm:"column:reference;type:varchar(255)" json:"reference"`
+	Description string    `gorm:"column:description;type:text" json:"description"`
+	MatchStatus string    `gorm:"column:match_status;type:varchar(50);index;not null" json:"match_status"`
+	PaymentID   *uint64   `gorm:"column:payment_id;index" json:"payment_id"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type BorrowerProfile struct {
+	ID                 uint64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID             uint64     `gorm:"column:user_id;uniqueIndex;not null" json:"user_id"`
+	NIK                string     `gorm:"column:nik;type:varchar(16);uniqueIndex;not null" json:"nik"`
+	PhoneNumber        string     `gorm:"column:phone_number;type:varchar(20);not null" json:"phone_number"`
+	DateOfBirth        time.Time  `gorm:"column:date_of_birth;type:date;not null" json:"date_of_birth"`
+	Address            string     `gorm:"column:address;type:text;not null" json:"address"`
+	EmploymentStatus   string     `gorm:"column:employment_status;type:varchar(50);not null" json:"employment_status"`
+	EmployerName       string     `gorm:"column:employer_name;type:varchar(255)" json:"employer_name"`
+	MonthlyIncome      float64    `gorm:"column:monthly_income;type:numeric(20,2);not null;default:0" json:"monthly_income"`
+	RiskGrade          string     `gorm:"column:risk_grade;type:varchar(10)" json:"risk_grade"`
+	RiskGradedBy       *uint64    `gorm:"column:risk_graded_by" json:"risk_graded_by"`
+	RiskGradedAt       *time.Time `gorm:"column:risk_graded_at" json:"risk_graded_at"`
+	KYCStatus          string     `gorm:"column:kyc_status;type:varchar(50);default:UNVERIFIED;not null" json:"kyc_status"`
+	KYCReviewedBy      *uint64    `gorm:"column:kyc_reviewed_by" json:"kyc_reviewed_by"`
+	KYCReviewedAt      *time.Time `gorm:"column:kyc_reviewed_at" json:"kyc_reviewed_at"`
+	KYCRejectionReason string     `gorm:"column:kyc_rejection_reason;type:text" json:"kyc_rejection_reason"`
+	CreatedAt          time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type CreditBalance struct {
+	ID        uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint64    `gorm:"column:user_id;uniqueIndex;not null" json:"user_id"`
+	Balance   float64   `gorm:"column:balance;type:numeric(20,2);not null;default:0" json:"balance"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
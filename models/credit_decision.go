@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type CreditDecision struct {
+	ID            uint64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID        uint64     `gorm:"column:user_id;index;not null" json:"user_id"`
+	RuleVersionID *uint64    `gorm:"column:rule_version_id;index" json:"rule_version_id"`
+	Decision      string     `gorm:"column:decision;type:varchar(50);not null" json:"decision"`
+	RiskGrade     string     `gorm:"column:risk_grade;type:varchar(10)" json:"risk_grade"`
+	Reasons       string     `gorm:"column:reasons;type:text;not null" json:"reasons"`
+	FiredRules    string     `gorm:"column:fired_rules;type:text;not null" json:"fired_rules"`
+	Facts         string     `gorm:"column:facts;type:text;not null" json:"facts"`
+	Application   string     `gorm:"column:application;type:text;not null" json:"application"`
+	LoanID        *uint64    `gorm:"column:loan_id;index" json:"loan_id"`
+	ReviewStatus  string     `gorm:"column:review_status;type:varchar(50);default:NOT_REQUIRED;not null;index" json:"review_status"`
+	ReviewedBy    *uint64    `gorm:"column:reviewed_by" json:"reviewed_by"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+	ReviewNote    string     `gorm:"column:review_note;type:text" json:"review_note"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type CreditLimit struct {
+	ID          uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID      uint64    `gorm:"column:user_id;uniqueIndex;not null" json:"user_id"`
+	LimitAmount float64   `gorm:"column:limit_amount;type:numeric(20,2);not null" json:"limit_amount"`
+	UpdatedBy   uint64    `gorm:"column:updated_by;not null" json:"updated_by"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type CreditLine struct {
+	ID                   uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID               uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	LineCode             string    `gorm:"column:line_code;type:varchar(255);uniqueIndex;not null" json:"line_code"`
+	LimitAmount          float64   `gorm:"column:limit_amount;type:numeric(20,2);not null" json:"limit_amount"`
+	InterestRate         float64   `gorm:"column:interest_rate;type:numeric(20,2);not null" json:"interest_rate"`
+	MinimumPaymentRate   float64   `gorm:"column:minimum_payment_rate;type:numeric(20,2);not null" json:"minimum_payment_rate"`
+	StatementDay         int       `gorm:"column:statement_day;not null" json:"statement_day"`
+	NextStatementDate    time.Time `gorm:"column:next_statement_date;not null" json:"next_statement_date"`
+	OutstandingPrincipal float64   `gorm:"column:outstanding_principal;type:numeric(20,2);default:0;not null" json:"outstanding_principal"`
+	InterestDue          float64   `gorm:"column:interest_due;type:numeric(20,2);default:0;not null" json:"interest_due"`
+	LineStatus           string    `gorm:"column:line_status;type:varchar(50);default:ACTIVE;not null" json:"line_status"`
+	CreatedAt            time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt            time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
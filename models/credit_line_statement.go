@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type CreditLineStatement struct {
+	ID               uint64    `gorm:"primaryKey;column:id" json:"id"`
+	CreditLineID     uint64    `gorm:"column:credit_line_id;index;not null" json:"credit_line_id"`
+	PeriodStart      time.Time `gorm:"column:period_start;not null" json:"period_start"`
+	PeriodEnd        time.Time `gorm:"column:period_end;not null" json:"period_end"`
+	OpeningBalance   float64   `gorm:"column:opening_balance;type:numeric(20,2);not null" json:"opening_balance"`
+	TotalDrawdowns   float64   `gorm:"column:total_drawdowns;type:numeric(20,2);not null" json:"total_drawdowns"`
+	TotalPayments    float64   `gorm:"column:total_payments;type:numeric(20,2);not null" json:"total_payments"`
+	TotalConversions float64   `gorm:"column:total_conversions;type:numeric(20,2);not null" json:"total_conversions"`
+	InterestCharged  float64   `gorm:"column:interest_charged;type:numeric(20,2);not null" json:"interest_charged"`
+	ClosingPrincipal float64   `gorm:"column:closing_principal;type:numeric(20,2);not null" json:"closing_principal"`
+	ClosingInterest  float64   `gorm:"column:closing_interest;type:numeric(20,2);not null" json:"closing_interest"`
+	ClosingBalance   float64   `gorm:"column:closing_balance;type:numeric(20,2);not null" json:"closing_balance"`
+	MinimumDue       float64   `gorm:"column:minimum_due;type:numeric(20,2);not null" json:"minimum_due"`
+	DueDate          time.Time `gorm:"column:due_date;not null" json:"due_date"`
+	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type CreditLineTransaction struct {
+	ID              uint64    `gorm:"primaryKey;column:id" json:"id"`
+	CreditLineID    uint64    `gorm:"column:credit_line_id;index;not null" json:"credit_line_id"`
+	DrawdownID      *uint64   `gorm:"column:drawdown_id" json:"drawdown_id"`
+	TransactionType string    `gorm:"column:transaction_type;type:varchar(50);not null" json:"transaction_type"`
+	PrincipalAmount float64   `gorm:"column:principal_amount;type:numeric(20,2);not null" json:"principal_amount"`
+	InterestAmount  float64   `gorm:"column:interest_amount;type:numeric(20,2);not null" json:"interest_amount"`
+	PrincipalAfter  float64   `gorm:"column:principal_after;type:numeric(20,2);not null" json:"principal_after"`
+	InterestAfter   float64   `gorm:"column:interest_after;type:numeric(20,2);not null" json:"interest_after"`
+	Reference       string    `gorm:"column:reference;type:varchar(255)" json:"reference"`
+	CreatedAt       time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type CreditRefund struct {
+	ID            uint64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID        uint64     `gorm:"column:user_id;index;not null" json:"user_id"`
+	Amount        float64    `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	BankName      string     `gorm:"column:bank_name;type:varchar(100);not null" json:"bank_name"`
+	AccountNumber string     `gorm:"column:account_number;type:varchar(100);not null" json:"account_number"`
+	AccountName   string     `gorm:"column:account_name;type:varchar(255);not null" json:"account_name"`
+	RefundStatus  string     `gorm:"column:refund_status;type:varchar(50);default:REQUESTED;not null" json:"refund_status"`
+	TransferRef   string     `gorm:"column:transfer_ref;type:varchar(255)" json:"transfer_ref"`
+	CompletedAt   *time.Time `gorm:"column:completed_at" json:"completed_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type CreditRuleVersion struct {
+	ID            uint64     `gorm:"primaryKey;column:id" json:"id"`
+	VersionNumber int        `gorm:"column:version_number;uniqueIndex;not null" json:"version_number"`
+	RuleSetName   string     `gorm:"column:rule_set_name;type:varchar(255)" json:"rule_set_name"`
+	Definition    string     `gorm:"column:definition;type:text;not null" json:"definition"`
+	Checksum      string     `gorm:"column:checksum;type:varchar(64);not null" json:"checksum"`
+	VersionStatus string     `gorm:"column:version_status;type:varchar(50);default:ACTIVE;not null;uniqueIndex:idx_credit_rule_versions_active,where:version_status = 'ACTIVE'" json:"version_status"`
+	Source        string     `gorm:"column:source;type:varchar(50);not null" json:"source"`
+	CreatedBy     *uint64    `gorm:"column:created_by" json:"created_by"`
+	RetiredAt     *time.Time `gorm:"column:retired_at" json:"retired_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+}
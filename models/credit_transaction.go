@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type CreditTransaction struct {
+	ID              uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID          uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	LoanID          *uint64   `gorm:"column:loan_id" json:"loan_id"`
+	PaymentID       *uint64   `gorm:"column:payment_id" json:"payment_id"`
+	RefundID        *uint64   `gorm:"column:refund_id" json:"refund_id"`
+	TransactionType string    `gorm:"column:transaction_type;type:varchar(50);not null" json:"transaction_type"`
+	Amount          float64   `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	BalanceAfter    float64   `gorm:"column:balance_after;type:numeric(20,2);not null" json:"balance_after"`
+	Note            string    `gorm:"column:note;type:varchar(255)" json:"note"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type DebitAttempt struct {
+	ID            uint64     `gorm:"primaryKey;column:id" json:"id"`
+	MandateID     uint64     `gorm:"column:mandate_id;index;not null" json:"mandate_id"`
+	LoanID        uint64     `gorm:"column:loan_id;not null" json:"loan_id"`
+	InstallmentID uint64     `gorm:"column:installment_id;uniqueIndex:idx_debit_attempts_installment_attempt;not null" json:"installment_id"`
+	AttemptNumber int        `gorm:"column:attempt_number;uniqueIndex:idx_debit_attempts_installment_attempt;not null" json:"attempt_number"`
+	Amount        float64    `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	AttemptStatus string     `gorm:"column:attempt_status;type:varchar(50);index;not null" json:"attempt_status"`
+	ScheduledAt   time.Time  `gorm:"column:scheduled_at;not null" json:"scheduled_at"`
+	AttemptedAt   *time.Time `gorm:"column:attempted_at" json:"attempted_at"`
+	ProviderRef   string     `gorm:"column:provider_ref;type:varchar(255)" json:"provider_ref"`
+	FailureReason string     `gorm:"column:failure_reason;type:varchar(255)" json:"failure_reason"`
+	PaymentID     *uint64    `gorm:"column:payment_id" json:"payment_id"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type DebitMandate struct {
+	ID            uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID        uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	LoanID        *uint64   `gorm:"column:loan_id;index" json:"loan_id"`
+	BankCode      string    `gorm:"column:bank_code;type:varchar(50);not null" json:"bank_code"`
+	AccountNumber string    `gorm:"column:account_number;type:varchar(100);not null" json:"account_number"`
+	AccountName   string    `gorm:"column:account_name;type:varchar(255);not null" json:"account_name"`
+	MaxAmount     float64   `gorm:"column:max_amount;type:numeric(20,2);not null" json:"max_amount"`
+	MandateStatus string    `gorm:"column:mandate_status;type:varchar(50);default:ACTIVE;not null" json:"mandate_status"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Drawdown struct {
+	ID             uint64    `gorm:"primaryKey;column:id" json:"id"`
+	CreditLineID   uint64    `gorm:"column:credit_line_id;index;not null" json:"credit_line_id"`
+	UserID         uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	Amount         float64   `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	PrincipalPaid  float64   `gorm:"column:principal_paid;type:numeric(20,2);default:0;not null" json:"principal_paid"`
+	Description    string    `gorm:"column:description;type:varchar(255)" json:"description"`
+	DrawdownStatus string    `gorm:"column:drawdown_status;type:varchar(50);default:OPEN;not null" json:"drawdown_status"`
+	LoanID         *uint64   `gorm:"column:loan_id" json:"loan_id"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Holiday struct {
+	ID          uint64    `gorm:"primaryKey;column:id" json:"id"`
+	HolidayDate time.Time `gorm:"column:holiday_date;type:date;uniqueIndex;not null" json:"holiday_date"`
+	HolidayName string    `gorm:"column:holiday_name;type:varchar(255)" json:"holiday_name"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -6,6 +6,7 @@ type Installment struct {
 	ID                    uint64    `gorm:"primaryKey;column:id" json:"id"`
 	LoanID                uint64    `gorm:"column:loan_id;not null" json:"loan_id"`
 	UserID                uint64    `gorm:"column:user_id;not null" json:"user_id"`
+	ScheduleVersion       int       `gorm:"column:schedule_version;default:1;not null" json:"schedule_version"`
 	Sequence              int       `gorm:"column:sequence;not null" json:"sequence"`
 	InstallmentAmount     float64   `gorm:"column:installment_amount;type:numeric(20,2);not null" json:"installment_amount"`
 	InterestAmount        float64   `gorm:"column:interest_amount;type:numeric(20,2);not null" json:"interest_amount"`
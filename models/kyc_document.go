@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type KYCDocument struct {
+	ID              uint64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID          uint64     `gorm:"column:user_id;index;not null" json:"user_id"`
+	DocumentType    string     `gorm:"column:document_type;type:varchar(50);not null" json:"document_type"`
+	StorageKey      string     `gorm:"column:storage_key;type:varchar(255);uniqueIndex;not null" json:"-"`
+	FileName        string     `gorm:"column:file_name;type:varchar(255);not null" json:"file_name"`
+	ContentType     string     `gorm:"column:content_type;type:varchar(100);not null" json:"content_type"`
+	FileSize        int64      `gorm:"column:file_size;not null" json:"file_size"`
+	Checksum        string     `gorm:"column:checksum;type:varchar(64);not null" json:"checksum"`
+	DocumentStatus  string     `gorm:"column:document_status;type:varchar(50);default:PENDING;not null" json:"document_status"`
+	ReviewedBy      *uint64    `gorm:"column:reviewed_by" json:"reviewed_by"`
+	ReviewedAt      *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+	RejectionReason string     `gorm:"column:rejection_reason;type:text" json:"rejection_reason"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -3,17 +3,25 @@ package models
 import "time"
 
 type Loan struct {
-	ID         uint64    `gorm:"primaryKey;column:id" json:"id"`
-	UserID     uint64    `gorm:"column:user_id;not null" json:"user_id"`
-	User       User      `gorm:"foreignKey:UserID" json:"user"`
-	PricingID  uint64    `gorm:"column:pricing_id;not null" json:"pricing_id"`
-	Pricing    Pricing   `gorm:"foreignKey:PricingID" json:"pricing"`
-	LoanCode   string    `gorm:"column:loan_code;type:varchar(255);uniqueIndex;not null" json:"loan_code"`
-	LoanStatus string    `gorm:"column:loan_status;type:varchar(255);default:PENDING;not null" json:"loan_status"`
-	LoanAmount float64   `gorm:"column:loan_amount;type:numeric(20,2);not null" json:"loan_amount"`
-	LoanLength int       `gorm:"column:loan_length;type:integer;not null" json:"loan_length"`
-	NTFTotal   float64   `gorm:"column:ntf_total;type:numeric(20,2)" json:"ntf_total"`
-	AdminTotal float64   `gorm:"column:admin_total;type:numeric(20,2)" json:"admin_total"`
-	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+	ID              uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID          uint64    `gorm:"column:user_id;not null" json:"user_id"`
+	User            User      `gorm:"foreignKey:UserID" json:"user"`
+	PricingID       uint64    `gorm:"column:pricing_id;not null" json:"pricing_id"`
+	Pricing         Pricing   `gorm:"foreignKey:PricingID" json:"pricing"`
+	ProductID       *uint64   `gorm:"column:product_id;index" json:"product_id"`
+	CreditLineID    *uint64   `gorm:"column:credit_line_id;index" json:"credit_line_id"`
+	RefinancedFrom  *uint64   `gorm:"column:refinanced_from;index" json:"refinanced_from"`
+	LoanCode        string    `gorm:"column:loan_code;type:varchar(255);uniqueIndex;not null" json:"loan_code"`
+	LoanStatus      string    `gorm:"column:loan_status;type:varchar(255);default:PENDING;not null" json:"loan_status"`
+	LoanAmount      float64   `gorm:"column:loan_amount;type:numeric(20,2);not null" json:"loan_amount"`
+	LoanLength      int       `gorm:"column:loan_length;type:integer;not null" json:"loan_length"`
+	NTFTotal        float64   `gorm:"column:ntf_total;type:numeric(20,2)" json:"ntf_total"`
+	AdminTotal      float64   `gorm:"column:admin_total;type:numeric(20,2)" json:"admin_total"`
+	PromoCodeID     *uint64   `gorm:"column:promo_code_id;index" json:"promo_code_id"`
+	RiskGrade       string    `gorm:"column:risk_grade;type:varchar(10)" json:"risk_grade"`
+	InterestRate    *float64  `gorm:"column:interest_rate;type:numeric(20,2)" json:"interest_rate"`
+	AdminRate       *float64  `gorm:"column:admin_rate;type:numeric(20,2)" json:"admin_rate"`
+	ScheduleVersion int       `gorm:"column:schedule_version;default:1;not null" json:"schedule_version"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
 }
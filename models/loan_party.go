@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type LoanParty struct {
+	ID        uint64    `gorm:"primaryKey;column:id" json:"id"`
+	LoanID    uint64    `gorm:"column:loan_id;not null;uniqueIndex:idx_loan_parties_loan_user" json:"loan_id"`
+	UserID    uint64    `gorm:"column:user_id;not null;uniqueIndex:idx_loan_parties_loan_user;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user"`
+	PartyRole string    `gorm:"column:party_role;type:varchar(50);not null" json:"party_role"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type LoanRecovery struct {
+	ID           uint64    `gorm:"primaryKey;column:id" json:"id"`
+	LoanID       uint64    `gorm:"column:loan_id;index;not null" json:"loan_id"`
+	UserID       uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	RecoveryCode string    `gorm:"column:recovery_code;type:varchar(255);uniqueIndex;not null" json:"recovery_code"`
+	Amount       float64   `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	Reference    string    `gorm:"column:reference;type:varchar(255)" json:"reference"`
+	Note         string    `gorm:"column:note;type:text" json:"note"`
+	RecordedBy   uint64    `gorm:"column:recorded_by;not null" json:"recorded_by"`
+	ReceivedAt   time.Time `gorm:"column:received_at;not null" json:"received_at"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+}
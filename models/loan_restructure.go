@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type LoanRestructure struct {
+	ID                   uint64    `gorm:"primaryKey;column:id" json:"id"`
+	LoanID               uint64    `gorm:"column:loan_id;index;not null" json:"loan_id"`
+	PreviousVersion      int       `gorm:"column:previous_version;not null" json:"previous_version"`
+	NewVersion           int       `gorm:"column:new_version;not null" json:"new_version"`
+	OutstandingPrincipal float64   `gorm:"column:outstanding_principal;type:numeric(20,2);not null" json:"outstanding_principal"`
+	CapitalizedInterest  float64   `gorm:"column:capitalized_interest;type:numeric(20,2);not null" json:"capitalized_interest"`
+	AccruedInterest      float64   `gorm:"column:accrued_interest;type:numeric(20,2);default:0;not null" json:"accrued_interest"`
+	PreviousInterestRate float64   `gorm:"column:previous_interest_rate;type:numeric(20,2);not null" json:"previous_interest_rate"`
+	NewInterestRate      float64   `gorm:"column:new_interest_rate;type:numeric(20,2);not null" json:"new_interest_rate"`
+	PreviousTenor        int       `gorm:"column:previous_tenor;not null" json:"previous_tenor"`
+	NewTenor             int       `gorm:"column:new_tenor;not null" json:"new_tenor"`
+	GracePeriods         int       `gorm:"column:grace_periods;not null" json:"grace_periods"`
+	Reason               string    `gorm:"column:reason;type:text;not null" json:"reason"`
+	ApprovedBy           uint64    `gorm:"column:approved_by;not null" json:"approved_by"`
+	ApprovedAt           time.Time `gorm:"column:approved_at;not null" json:"approved_at"`
+	CreatedAt            time.Time `gorm:"column:created_at" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type LoanTopUp struct {
+	ID                   uint64    `gorm:"primaryKey;column:id" json:"id"`
+	PreviousLoanID       uint64    `gorm:"column:previous_loan_id;uniqueIndex;not null" json:"previous_loan_id"`
+	NewLoanID            uint64    `gorm:"column:new_loan_id;uniqueIndex;not null" json:"new_loan_id"`
+	SettlementPaymentID  uint64    `gorm:"column:settlement_payment_id;not null" json:"settlement_payment_id"`
+	OutstandingPrincipal float64   `gorm:"column:outstanding_principal;type:numeric(20,2);not null" json:"outstanding_principal"`
+	InterestSettled      float64   `gorm:"column:interest_settled;type:numeric(20,2);not null" json:"interest_settled"`
+	PayoffAmount         float64   `gorm:"column:payoff_amount;type:numeric(20,2);not null" json:"payoff_amount"`
+	NewLoanAmount        float64   `gorm:"column:new_loan_amount;type:numeric(20,2);not null" json:"new_loan_amount"`
+	NetDisbursement      float64   `gorm:"column:net_disbursement;type:numeric(20,2);not null" json:"net_disbursement"`
+	CreatedAt            time.Time `gorm:"column:created_at" json:"created_at"`
+}
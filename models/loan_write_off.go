@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type LoanWriteOff struct {
+	ID                  uint64    `gorm:"primaryKey;column:id" json:"id"`
+	LoanID              uint64    `gorm:"column:loan_id;uniqueIndex;not null" json:"loan_id"`
+	ScheduleVersion     int       `gorm:"column:schedule_version;not null" json:"schedule_version"`
+	WrittenOffPrincipal float64   `gorm:"column:written_off_principal;type:numeric(20,2);not null" json:"written_off_principal"`
+	WrittenOffInterest  float64   `gorm:"column:written_off_interest;type:numeric(20,2);not null" json:"written_off_interest"`
+	WrittenOffFees      float64   `gorm:"column:written_off_fees;type:numeric(20,2);not null" json:"written_off_fees"`
+	Reason              string    `gorm:"column:reason;type:text;not null" json:"reason"`
+	ApprovedBy          uint64    `gorm:"column:approved_by;not null" json:"approved_by"`
+	CreatedAt           time.Time `gorm:"column:created_at" json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type LoginAttempt struct {
+	ID            uint64    `gorm:"primaryKey;column:id" json:"id"`
+	AttemptKey    string    `gorm:"column:attempt_key;type:varchar(255);uniqueIndex;not null" json:"attempt_key"`
+	Failures      int       `gorm:"column:failures;not null;default:0" json:"failures"`
+	LastFailureAt time.Time `gorm:"column:last_failure_at" json:"last_failure_at"`
+	LockedUntil   time.Time `gorm:"column:locked_until" json:"locked_until"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
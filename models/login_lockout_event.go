@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type LoginLockoutEvent struct {
+	ID           uint64    `gorm:"primaryKey;column:id" json:"id"`
+	LockoutScope string    `gorm:"column:lockout_scope;type:varchar(50);not null" json:"lockout_scope"`
+	AttemptKey   string    `gorm:"column:attempt_key;type:varchar(255);index;not null" json:"attempt_key"`
+	EmailAddress string    `gorm:"column:email_address;type:varchar(255)" json:"email_address"`
+	IPAddress    string    `gorm:"column:ip_address;type:varchar(100)" json:"ip_address"`
+	Failures     int       `gorm:"column:failures;not null" json:"failures"`
+	LockedUntil  time.Time `gorm:"column:locked_until;not null" json:"locked_until"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+}
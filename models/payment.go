@@ -3,11 +3,16 @@ package models
 import "time"
 
 type Payment struct {
-	ID            uint64    `gorm:"primaryKey;column:id" json:"id"`
-	UserID        uint64    `gorm:"column:user_id;not null" json:"user_id"`
-	LoanID        uint64    `gorm:"column:loan_id;not null" json:"loan_id"`
-	PaymentCode   string    `gorm:"column:payment_code;type:varchar(255);uniqueIndex;not null" json:"payment_code"`
-	PaymentAmount float64   `gorm:"column:payment_amount;type:numeric(20,2);not null" json:"payment_amount"`
-	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+	ID                uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID            uint64    `gorm:"column:user_id;not null" json:"user_id"`
+	LoanID            uint64    `gorm:"column:loan_id;not null" json:"loan_id"`
+	PaymentCode       string    `gorm:"column:payment_code;type:varchar(255);uniqueIndex;not null" json:"payment_code"`
+	PaymentAmount     float64   `gorm:"column:payment_amount;type:numeric(20,2);not null" json:"payment_amount"`
+	ExcessAmount      float64   `gorm:"column:excess_amount;type:numeric(20,2);not null;default:0" json:"excess_amount"`
+	PaymentChannel    string    `gorm:"column:payment_channel;type:varchar(50);default:DIRECT;not null" json:"payment_channel"`
+	PaidBy            *uint64   `gorm:"column:paid_by;index" json:"paid_by"`
+	PartnerCode       string    `gorm:"column:partner_code;type:varchar(100);uniqueIndex:idx_payments_partner_reference" json:"partner_code"`
+	ExternalReference *string   `gorm:"column:external_reference;type:varchar(255);uniqueIndex:idx_payments_partner_reference" json:"external_reference"`
+	CreatedAt         time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"column:updated_at" json:"updated_at"`
 }
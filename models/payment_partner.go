@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type PaymentPartner struct {
+	ID            uint64    `gorm:"primaryKey;column:id" json:"id"`
+	PartnerCode   string    `gorm:"column:partner_code;type:varchar(100);uniqueIndex;not null" json:"partner_code"`
+	PartnerName   string    `gorm:"column:partner_name;type:varchar(255);not null" json:"partner_name"`
+	VAPrefix      string    `gorm:"column:va_prefix;type:varchar(20)" json:"va_prefix"`
+	SigningSecret string    `gorm:"column:signing_secret;type:varchar(255);not null" json:"-"`
+	PartnerStatus string    `gorm:"column:partner_status;type:varchar(50);default:ACTIVE;not null" json:"partner_status"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
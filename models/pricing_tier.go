@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type PricingTier struct {
+	ID           uint64    `gorm:"primaryKey;column:id" json:"id"`
+	ProductID    uint64    `gorm:"column:product_id;not null;uniqueIndex:idx_pricing_tiers_cell" json:"product_id"`
+	RiskGrade    string    `gorm:"column:risk_grade;type:varchar(10);not null;uniqueIndex:idx_pricing_tiers_cell" json:"risk_grade"`
+	MinTenor     int       `gorm:"column:min_tenor;not null;uniqueIndex:idx_pricing_tiers_cell" json:"min_tenor"`
+	MaxTenor     int       `gorm:"column:max_tenor;not null" json:"max_tenor"`
+	InterestRate float64   `gorm:"column:interest_rate;type:numeric(20,2);not null" json:"interest_rate"`
+	AdminRate    float64   `gorm:"column:admin_rate;type:numeric(20,2);not null" json:"admin_rate"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Product struct {
+	ID                  uint64    `gorm:"primaryKey;column:id" json:"id"`
+	ProductCode         string    `gorm:"column:product_code;type:varchar(100);uniqueIndex;not null" json:"product_code"`
+	ProductName         string    `gorm:"column:product_name;type:varchar(255);not null" json:"product_name"`
+	PricingID           uint64    `gorm:"column:pricing_id;not null" json:"pricing_id"`
+	Pricing             Pricing   `gorm:"foreignKey:PricingID" json:"pricing"`
+	GracePeriods        int       `gorm:"column:grace_periods;default:0;not null" json:"grace_periods"`
+	GraceInterestMode   string    `gorm:"column:grace_interest_mode;type:varchar(50);default:CAPITALIZED;not null" json:"grace_interest_mode"`
+	InterestOnlyPeriods int       `gorm:"column:interest_only_periods;default:0;not null" json:"interest_only_periods"`
+	DateAdjustment      string    `gorm:"column:date_adjustment;type:varchar(50)" json:"date_adjustment"`
+	DayCountConvention  string    `gorm:"column:day_count_convention;type:varchar(20)" json:"day_count_convention"`
+	OneActiveLoan       bool      `gorm:"column:one_active_loan;default:false;not null" json:"one_active_loan"`
+	ProductStatus       string    `gorm:"column:product_status;type:varchar(50);default:ACTIVE;not null" json:"product_status"`
+	CreatedAt           time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type PromoCode struct {
+	ID               uint64    `gorm:"primaryKey;column:id" json:"id"`
+	PromoCode        string    `gorm:"column:promo_code;type:varchar(100);uniqueIndex;not null" json:"promo_code"`
+	CampaignName     string    `gorm:"column:campaign_name;type:varchar(255);not null" json:"campaign_name"`
+	DiscountType     string    `gorm:"column:discount_type;type:varchar(50);not null" json:"discount_type"`
+	DiscountPercent  float64   `gorm:"column:discount_percent;type:numeric(5,2);not null" json:"discount_percent"`
+	InstallmentCount int       `gorm:"column:installment_count;default:0;not null" json:"installment_count"`
+	ValidFrom        time.Time `gorm:"column:valid_from;not null" json:"valid_from"`
+	ValidUntil       time.Time `gorm:"column:valid_until;not null" json:"valid_until"`
+	MaxRedemptions   int       `gorm:"column:max_redemptions;default:0;not null" json:"max_redemptions"`
+	MaxPerUser       int       `gorm:"column:max_per_user;default:1;not null" json:"max_per_user"`
+	RedemptionCount  int       `gorm:"column:redemption_count;default:0;not null" json:"redemption_count"`
+	ProductID        *uint64   `gorm:"column:product_id" json:"product_id"`
+	MinLoanAmount    float64   `gorm:"column:min_loan_amount;type:numeric(20,2);default:0;not null" json:"min_loan_amount"`
+	MaxLoanAmount    float64   `gorm:"column:max_loan_amount;type:numeric(20,2);default:0;not null" json:"max_loan_amount"`
+	NewBorrowersOnly bool      `gorm:"column:new_borrowers_only;default:false;not null" json:"new_borrowers_only"`
+	RiskGrades       string    `gorm:"column:risk_grades;type:varchar(255)" json:"risk_grades"`
+	PromoStatus      string    `gorm:"column:promo_status;type:varchar(50);default:ACTIVE;not null" json:"promo_status"`
+	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type PromoRedemption struct {
+	ID               uint64    `gorm:"primaryKey;column:id" json:"id"`
+	PromoCodeID      uint64    `gorm:"column:promo_code_id;index;not null" json:"promo_code_id"`
+	UserID           uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	LoanID           uint64    `gorm:"column:loan_id;uniqueIndex;not null" json:"loan_id"`
+	AdminFeeDiscount float64   `gorm:"column:admin_fee_discount;type:numeric(20,2);not null" json:"admin_fee_discount"`
+	InterestDiscount float64   `gorm:"column:interest_discount;type:numeric(20,2);not null" json:"interest_discount"`
+	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
+}
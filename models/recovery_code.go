@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type RecoveryCode struct {
+	ID        uint64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint64     `gorm:"column:user_id;index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"column:code_hash;type:varchar(255);uniqueIndex;not null" json:"-"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ScheduleVersion struct {
+	ID            uint64     `gorm:"primaryKey;column:id" json:"id"`
+	LoanID        uint64     `gorm:"column:loan_id;uniqueIndex:idx_schedule_versions_loan_version;uniqueIndex:idx_schedule_versions_active,where:version_status = 'ACTIVE';not null" json:"loan_id"`
+	VersionNumber int        `gorm:"column:version_number;uniqueIndex:idx_schedule_versions_loan_version;not null" json:"version_number"`
+	VersionStatus string     `gorm:"column:version_status;type:varchar(50);default:ACTIVE;not null" json:"version_status"`
+	ChangeType    string     `gorm:"column:change_type;type:varchar(50);not null" json:"change_type"`
+	Note          string     `gorm:"column:note;type:text" json:"note"`
+	SupersededAt  *time.Time `gorm:"column:superseded_at" json:"superseded_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type SignedRequest struct {
+	ID          uint64    `gorm:"primaryKey;column:id" json:"id"`
+	PartnerCode string    `gorm:"column:partner_code;type:varchar(100);not null" json:"partner_code"`
+	Signature   string    `gorm:"column:signature;type:varchar(255);uniqueIndex;not null" json:"signature"`
+	CreatedAt   time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
@@ -3,11 +3,17 @@ package models
 import "time"
 
 type User struct {
-	ID           uint64    `gorm:"primaryKey;column:id" json:"id"`
-	FullName     string    `gorm:"column:full_name;type:varchar(255)" json:"full_name"`
-	EmailAddress string    `gorm:"column:email_address;type:varchar(255);uniqueIndex" json:"email_address"`
-	PasswordHash string    `gorm:"column:password_hash;type:varchar(255)" json:"-"`
-	PasswordSalt string    `gorm:"column:password_salt;type:varchar(255)" json:"-"`
-	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"column:updated_at" json:"updated_at"`
+	ID              uint64     `gorm:"primaryKey;column:id" json:"id"`
+	FullName        string     `gorm:"column:full_name;type:varchar(255)" json:"full_name"`
+	EmailAddress    string     `gorm:"column:email_address;type:varchar(255);uniqueIndex" json:"email_address"`
+	PasswordHash    string     `gorm:"column:password_hash;type:varchar(255)" json:"-"`
+	PasswordSalt    string     `gorm:"column:password_salt;type:varchar(255)" json:"-"`
+	Role            string     `gorm:"column:role;type:varchar(50);default:BORROWER;not null" json:"role"`
+	UserStatus      string     `gorm:"column:user_status;type:varchar(50);default:ACTIVE;not null" json:"user_status"`
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at" json:"email_verified_at"`
+	TOTPSecret      string     `gorm:"column:totp_secret;type:varchar(255)" json:"-"`
+	TOTPEnabled     bool       `gorm:"column:totp_enabled;not null;default:false" json:"totp_enabled"`
+	TOTPLastStep    int64      `gorm:"column:totp_last_step;not null;default:0" json:"-"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type UserToken struct {
+	ID           uint64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID       uint64     `gorm:"column:user_id;index;not null" json:"user_id"`
+	TokenPurpose string     `gorm:"column:token_purpose;type:varchar(50);not null" json:"token_purpose"`
+	TokenHash    string     `gorm:"column:token_hash;type:varchar(255);uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;not null" json:"expires_at"`
+	UsedAt       *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at" json:"created_at"`
+}
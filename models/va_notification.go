@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type VANotification struct {
+	ID             uint64     `gorm:"primaryKey;column:id" json:"id"`
+	PartnerCode    string     `gorm:"column:partner_code;type:varchar(100);index:idx_va_notifications_transaction;not null" json:"partner_code"`
+	TransactionID  string     `gorm:"column:transaction_id;type:varchar(255);index:idx_va_notifications_transaction;not null" json:"transaction_id"`
+	VANumber       string     `gorm:"column:va_number;type:varchar(50);index;not null" json:"va_number"`
+	Amount         float64    `gorm:"column:amount;type:numeric(20,2);not null" json:"amount"`
+	PaidAt         time.Time  `gorm:"column:paid_at;not null" json:"paid_at"`
+	RawPayload     string     `gorm:"column:raw_payload;type:text" json:"raw_payload"`
+	ProcessStatus  string     `gorm:"column:process_status;type:varchar(50);index;not null" json:"process_status"`
+	SuspenseReason string     `gorm:"column:suspense_reason;type:varchar(255)" json:"suspense_reason"`
+	LoanID         *uint64    `gorm:"column:loan_id" json:"loan_id"`
+	PaymentID      *uint64    `gorm:"column:payment_id" json:"payment_id"`
+	ResolvedAt     *time.Time `gorm:"column:resolved_at" json:"resolved_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
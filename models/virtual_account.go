@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type VirtualAccount struct {
+	ID          uint64    `gorm:"primaryKey;column:id" json:"id"`
+	UserID      uint64    `gorm:"column:user_id;index;not null" json:"user_id"`
+	LoanID      *uint64   `gorm:"column:loan_id;index" json:"loan_id"`
+	PartnerCode string    `gorm:"column:partner_code;type:varchar(100);not null" json:"partner_code"`
+	VANumber    string    `gorm:"column:va_number;type:varchar(50);uniqueIndex;not null" json:"va_number"`
+	VAStatus    string    `gorm:"column:va_status;type:varchar(50);default:ACTIVE;not null" json:"va_status"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
@@ -14,21 +14,208 @@ func SetupRoutes(r *gin.Engine) {
 
 	r.POST("/register", handlers.Register)
 	r.POST("/login", handlers.Login)
+	r.POST("/login/totp", handlers.VerifyLoginTOTP)
+	r.POST("/verify-email", handlers.VerifyEmail)
+	r.POST("/verify-email/resend", handlers.ResendVerificationEmail)
+	r.POST("/password/forgot", handlers.ForgotPassword)
+	r.POST("/password/reset", handlers.ResetPassword)
+
+	totpGroup := r.Group("/auth/totp")
+	totpGroup.Use(middlewares.AuthMiddleware())
+	{
+		totpGroup.POST("/enroll", handlers.EnrollTOTP)
+		totpGroup.POST("/confirm", handlers.ConfirmTOTP)
+	}
+
+	profileGroup := r.Group("/profile")
+	profileGroup.Use(middlewares.AuthMiddleware())
+	{
+		profileGroup.GET("/", handlers.GetMyProfile)
+		profileGroup.PUT("/", handlers.UpsertMyProfile)
+		profileGroup.POST("/documents", handlers.UploadKYCDocument)
+		profileGroup.POST("/kyc/submit", handlers.SubmitKYC)
+	}
+
+	kycGroup := r.Group("/kyc")
+	kycGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		kycGroup.GET("/profiles/:user_id", handlers.GetBorrowerProfile)
+		kycGroup.POST("/profiles/:user_id/review", handlers.ReviewKYC)
+		kycGroup.PUT("/profiles/:user_id/risk-grade", handlers.SetBorrowerRiskGrade)
+		kycGroup.GET("/documents/:id/file", handlers.DownloadKYCDocument)
+		kycGroup.POST("/documents/:id/review", handlers.ReviewKYCDocument)
+	}
 
 	pricingGroup := r.Group("/pricings")
 	pricingGroup.Use(middlewares.AuthMiddleware())
 	{
-		pricingGroup.POST("/upsert", handlers.UpsertPricing)
+		pricingGroup.POST("/upsert", middlewares.RequireRole("ADMIN"), handlers.UpsertPricing)
+	}
+
+	productGroup := r.Group("/products")
+	productGroup.Use(middlewares.AuthMiddleware())
+	{
+		productGroup.GET("/", handlers.GetAllProducts)
+		productGroup.POST("/", middlewares.RequireRole("ADMIN"), handlers.CreateProduct)
+		productGroup.GET("/:code/pricing-tiers", handlers.GetPricingTiers)
+		productGroup.PUT("/:code/pricing-tiers", middlewares.RequireRole("ADMIN"), handlers.ReplacePricingTiers)
+	}
+
+	holidayGroup := r.Group("/holidays")
+	holidayGroup.Use(middlewares.AuthMiddleware())
+	{
+		holidayGroup.GET("/", handlers.GetHolidays)
+		holidayGroup.POST("/", middlewares.RequireRole("ADMIN"), handlers.CreateHoliday)
+		holidayGroup.POST("/import", middlewares.RequireRole("ADMIN"), handlers.ImportHolidays)
+		holidayGroup.DELETE("/:id", middlewares.RequireRole("ADMIN"), handlers.DeleteHoliday)
 	}
 
 	loanGroup := r.Group("/loans")
 	loanGroup.Use(middlewares.AuthMiddleware())
 	{
-		loanGroup.GET("/", handlers.GetAllLoans)
-		loanGroup.POST("/", handlers.CreateLoan)
-		loanGroup.GET("/:id", handlers.GetLoanDetail)
-		loanGroup.GET("/oustanding/:id", handlers.GetOutstanding)
-		loanGroup.POST("/payment/:loan_id", handlers.MakePayment)
-		loanGroup.GET("/delinquent/:loan_id", handlers.IsDelinquent)
+		loanGroup.GET("/", middlewares.RequireScope("loans:read"), handlers.GetAllLoans)
+		loanGroup.POST("/", middlewares.RequireScope("loans:write"), handlers.CreateLoan)
+		loanGroup.POST("/quote", middlewares.RequireScope("loans:read"), handlers.QuoteLoan)
+		loanGroup.POST("/simulate", middlewares.RequireScope("loans:read"), handlers.SimulateLoan)
+		loanGroup.GET("/:id", middlewares.RequireScope("loans:read"), handlers.GetLoanDetail)
+		loanGroup.GET("/oustanding/:id", middlewares.RequireScope("loans:read"), handlers.GetOutstanding)
+		loanGroup.POST("/payment/:loan_id", middlewares.RequireScope("payments:write"), handlers.MakePayment)
+		loanGroup.GET("/delinquent/:loan_id", middlewares.RequireScope("loans:read"), handlers.IsDelinquent)
+		loanGroup.GET("/:id/virtual-accounts", middlewares.RequireScope("loans:read"), handlers.GetLoanVirtualAccounts)
+		loanGroup.POST("/:id/virtual-accounts", middlewares.RequireScope("loans:write"), handlers.AssignVirtualAccount)
+		loanGroup.GET("/:id/payoff", middlewares.RequireScope("loans:read"), handlers.GetPayoffQuote)
+		loanGroup.GET("/:id/parties", middlewares.RequireScope("loans:read"), handlers.GetLoanParties)
+		loanGroup.POST("/:id/parties", middlewares.RequireRole("ADMIN"), handlers.AddLoanParty)
+		loanGroup.DELETE("/:id/parties/:user_id", middlewares.RequireRole("ADMIN"), handlers.RemoveLoanParty)
+		loanGroup.GET("/:id/top-ups", middlewares.RequireScope("loans:read"), handlers.GetLoanTopUps)
+		loanGroup.POST("/:id/top-up", middlewares.RequireScope("loans:write"), handlers.TopUpLoan)
+		loanGroup.GET("/:id/restructures", middlewares.RequireScope("loans:read"), handlers.GetLoanRestructures)
+		loanGroup.POST("/:id/restructure", middlewares.RequireRole("ADMIN"), handlers.RestructureLoan)
+		loanGroup.GET("/:id/write-off", middlewares.RequireRole("ADMIN"), handlers.GetLoanWriteOff)
+		loanGroup.POST("/:id/write-off", middlewares.RequireRole("ADMIN"), handlers.WriteOffLoan)
+		loanGroup.POST("/:id/recoveries", middlewares.RequireRole("ADMIN"), handlers.RecordLoanRecovery)
+	}
+
+	apiClientGroup := r.Group("/api-clients")
+	apiClientGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		apiClientGroup.GET("/", handlers.GetAllAPIClients)
+		apiClientGroup.POST("/", handlers.CreateAPIClient)
+		apiClientGroup.POST("/:id/revoke", handlers.RevokeAPIClient)
+	}
+
+	partnerGroup := r.Group("/payment-partners")
+	partnerGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		partnerGroup.GET("/", handlers.GetAllPaymentPartners)
+		partnerGroup.POST("/", handlers.CreatePaymentPartner)
+	}
+
+	callbackGroup := r.Group("/callbacks")
+	callbackGroup.Use(middlewares.SignatureMiddleware())
+	{
+		callbackGroup.POST("/payments", handlers.PaymentCallback)
+		callbackGroup.POST("/virtual-accounts", handlers.VirtualAccountCallback)
+	}
+
+	suspenseGroup := r.Group("/virtual-accounts/suspense")
+	suspenseGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		suspenseGroup.GET("/", handlers.GetSuspenseNotifications)
+		suspenseGroup.POST("/:id/resolve", handlers.ResolveSuspenseNotification)
+	}
+
+	reconciliationGroup := r.Group("/reconciliation")
+	reconciliationGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		reconciliationGroup.POST("/statements", handlers.ImportBankStatement)
+		reconciliationGroup.GET("/statements/:id", handlers.GetBankStatement)
+		reconciliationGroup.POST("/lines/:id/post", handlers.PostStatementLine)
+	}
+
+	r.GET("/credit-balance", middlewares.AuthMiddleware(), handlers.GetMyCreditBalance)
+
+	r.GET("/credit-limit", middlewares.AuthMiddleware(), handlers.GetMyCreditLimit)
+
+	creditLimitGroup := r.Group("/credit-limits")
+	creditLimitGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		creditLimitGroup.GET("/:user_id", handlers.GetCreditLimit)
+		creditLimitGroup.PUT("/:user_id", handlers.SetCreditLimit)
+	}
+
+	creditLineGroup := r.Group("/credit-lines")
+	creditLineGroup.Use(middlewares.AuthMiddleware())
+	{
+		creditLineGroup.GET("/", handlers.GetMyCreditLines)
+		creditLineGroup.POST("/", middlewares.RequireRole("ADMIN"), handlers.CreateCreditLine)
+		creditLineGroup.POST("/statements/run", middlewares.RequireRole("ADMIN"), handlers.RunCreditLineStatements)
+		creditLineGroup.GET("/:id", middlewares.RequireScope("loans:read"), handlers.GetCreditLine)
+		creditLineGroup.GET("/:id/statements", middlewares.RequireScope("loans:read"), handlers.GetCreditLineStatements)
+		creditLineGroup.POST("/:id/drawdowns", middlewares.RequireScope("loans:write"), handlers.CreateDrawdown)
+		creditLineGroup.POST("/:id/drawdowns/:drawdown_id/convert", middlewares.RequireScope("loans:write"), handlers.ConvertDrawdown)
+		creditLineGroup.POST("/:id/payments", middlewares.RequireScope("payments:write"), handlers.PayCreditLine)
+	}
+
+	creditGroup := r.Group("/credit-balances")
+	creditGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		creditGroup.POST("/apply-due", handlers.ApplyDueCreditBalances)
+		creditGroup.GET("/:user_id", handlers.GetCreditBalance)
+		creditGroup.POST("/:user_id/refunds", handlers.CreateCreditRefund)
+	}
+
+	refundGroup := r.Group("/credit-refunds")
+	refundGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		refundGroup.POST("/:id/complete", handlers.CompleteCreditRefund)
+		refundGroup.POST("/:id/cancel", handlers.CancelCreditRefund)
+	}
+
+	mandateGroup := r.Group("/mandates")
+	mandateGroup.Use(middlewares.AuthMiddleware())
+	{
+		mandateGroup.GET("/", handlers.GetMyDebitMandates)
+		mandateGroup.POST("/", middlewares.RequireScope("payments:write"), handlers.CreateDebitMandate)
+		mandateGroup.POST("/:id/revoke", handlers.RevokeDebitMandate)
+	}
+
+	collectionGroup := r.Group("/collections")
+	collectionGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		collectionGroup.POST("/run", handlers.RunCollections)
+		collectionGroup.GET("/attempts", handlers.GetDebitAttempts)
+		collectionGroup.GET("/loans/:id/contacts", handlers.GetLoanContacts)
+	}
+
+	creditRuleGroup := r.Group("/credit-rules")
+	creditRuleGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		creditRuleGroup.GET("/", handlers.GetCreditRuleVersions)
+		creditRuleGroup.POST("/", handlers.CreateCreditRuleVersion)
+	}
+
+	decisionGroup := r.Group("/credit-decisions")
+	decisionGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		decisionGroup.GET("/", handlers.GetCreditDecisions)
+		decisionGroup.GET("/:id", handlers.GetCreditDecision)
+		decisionGroup.POST("/:id/review", handlers.ReviewCreditDecision)
+	}
+
+	promoGroup := r.Group("/promo-codes")
+	promoGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		promoGroup.GET("/", handlers.GetPromoCodes)
+		promoGroup.POST("/", handlers.CreatePromoCode)
+		promoGroup.POST("/:id/deactivate", handlers.DeactivatePromoCode)
+		promoGroup.GET("/:id/redemptions", handlers.GetPromoRedemptions)
+	}
+
+	reportGroup := r.Group("/reports")
+	reportGroup.Use(middlewares.AuthMiddleware(), middlewares.RequireRole("ADMIN"))
+	{
+		reportGroup.GET("/portfolio", handlers.GetPortfolioReport)
+		reportGroup.GET("/promotions", handlers.GetPromotionsReport)
 	}
 }
@@ -23,3 +23,16 @@ func CheckPassword(inputPassword, salt, storedHash string) bool {
 	inputHash := HashPassword(inputPassword, salt)
 	return inputHash == storedHash
 }
+
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func GenerateSecureToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type HolidayEntry struct {
+	Date time.Time
+	Name string
+}
+
+// BusinessCalendar treats weekends and the listed holidays as non-business
+// days. A nil calendar treats every day as a business day.
+type BusinessCalendar struct {
+	holidays map[string]bool
+}
+
+func NewBusinessCalendar(holidays []time.Time) *BusinessCalendar {
+	calendar := &BusinessCalendar{holidays: make(map[string]bool, len(holidays))}
+	for _, day := range holidays {
+		calendar.holidays[day.Format("2006-01-02")] = true
+	}
+	return calendar
+}
+
+func (c *BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays[t.Format("2006-01-02")]
+}
+
+// AdjustDate moves t off a non-business day. FOLLOWING rolls forward to the
+// next business day; MODIFIED_FOLLOWING does the same unless that crosses
+// into the next month, in which case it rolls back instead. Any other
+// convention leaves t unchanged.
+func (c *BusinessCalendar) AdjustDate(t time.Time, convention string) time.Time {
+	if convention != "FOLLOWING" && convention != "MODIFIED_FOLLOWING" {
+		return t
+	}
+
+	adjusted := t
+	for !c.IsBusinessDay(adjusted) {
+		adjusted = adjusted.AddDate(0, 0, 1)
+	}
+
+	if convention == "MODIFIED_FOLLOWING" && adjusted.Month() != t.Month() {
+		adjusted = t
+		for !c.IsBusinessDay(adjusted) {
+			adjusted = adjusted.AddDate(0, 0, -1)
+		}
+	}
+
+	return adjusted
+}
+
+func ValidDateAdjustment(convention string) bool {
+	switch convention {
+	case "NONE", "FOLLOWING", "MODIFIED_FOLLOWING":
+		return true
+	}
+	return false
+}
+
+// ParseHolidayFile reads one holiday per line as "date,name" with the date in
+// YYYY-MM-DD form. Blank lines, lines starting with # and a header row are
+// skipped.
+func ParseHolidayFile(r io.Reader) ([]HolidayEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	var entries []HolidayEntry
+	for lineNumber := 1; ; lineNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		dateField := strings.TrimSpace(record[0])
+		if dateField == "" {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", dateField)
+		if err != nil {
+			if len(entries) == 0 && strings.EqualFold(dateField, "date") {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid date %q", lineNumber, dateField)
+		}
+
+		entry := HolidayEntry{Date: date}
+		if len(record) > 1 {
+			entry.Name = strings.TrimSpace(record[1])
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
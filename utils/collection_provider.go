@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type DebitRequest struct {
+	Reference     string
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Amount        float64
+}
+
+type DebitResult struct {
+	Succeeded     bool
+	ProviderRef   string
+	FailureReason string
+}
+
+// CollectionProvider pulls money from a borrower's bank account under an
+// authorised mandate. A returned error means the outcome is unknown; a failed
+// debit is reported through DebitResult instead.
+type CollectionProvider interface {
+	Debit(request DebitRequest) (DebitResult, error)
+}
+
+// FakeCollectionProvider succeeds for every account except those ending in
+// "000", which are treated as having insufficient funds.
+type FakeCollectionProvider struct {
+	mu      sync.Mutex
+	counter int
+}
+
+func NewFakeCollectionProvider() *FakeCollectionProvider {
+	return &FakeCollectionProvider{}
+}
+
+func (p *FakeCollectionProvider) Debit(request DebitRequest) (DebitResult, error) {
+	p.mu.Lock()
+	p.counter++
+	providerRef := fmt.Sprintf("FAKE-%s-%d", request.Reference, p.counter)
+	p.mu.Unlock()
+
+	if strings.HasSuffix(request.AccountNumber, "000") {
+		return DebitResult{ProviderRef: providerRef, FailureReason: "Insufficient funds"}, nil
+	}
+
+	return DebitResult{Succeeded: true, ProviderRef: providerRef}, nil
+}
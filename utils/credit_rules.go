@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CreditDecisionApprove      = "APPROVE"
+	CreditDecisionReject       = "REJECT"
+	CreditDecisionManualReview = "MANUAL_REVIEW"
+)
+
+// CreditFacts are the values a rule can test, keyed by field name.
+type CreditFacts map[string]float64
+
+// CreditFactFields lists every field the decision engine fills in for an
+// application. Rules may only refer to these.
+var CreditFactFields = []string{
+	"loan_amount",
+	"loan_length",
+	"installment_amount",
+	"monthly_income",
+	"amount_to_income",
+	"installment_to_income",
+	"age",
+	"kyc_verified",
+	"existing_exposure",
+	"active_loans",
+	"written_off_loans",
+	"late_installments",
+	"max_days_past_due",
+	"current_days_past_due",
+}
+
+type CreditCondition struct {
+	Field    string  `yaml:"field" json:"field"`
+	Operator string  `yaml:"operator" json:"operator"`
+	Value    float64 `yaml:"value" json:"value"`
+}
+
+type CreditRule struct {
+	Name            string `yaml:"name" json:"name"`
+	CreditCondition `yaml:",inline"`
+	Outcome         string `yaml:"outcome" json:"outcome"`
+	Reason          string `yaml:"reason" json:"reason"`
+}
+
+// CreditGrade assigns a risk grade to applications meeting every condition.
+type CreditGrade struct {
+	Grade string            `yaml:"grade" json:"grade"`
+	When  []CreditCondition `yaml:"when" json:"when"`
+}
+
+// CreditRuleSet is the configuration the engine runs. Each rule describes a
+// condition that, when true, rejects the application or sends it to manual
+// review. An application no rule fires on is approved. Grades are tried in
+// order and the first match sets the risk grade, falling back to
+// DefaultGrade.
+type CreditRuleSet struct {
+	Name         string        `yaml:"name" json:"name"`
+	Rules        []CreditRule  `yaml:"rules" json:"rules"`
+	Grades       []CreditGrade `yaml:"grades" json:"grades"`
+	DefaultGrade string        `yaml:"default_grade" json:"default_grade"`
+}
+
+type CreditEvaluation struct {
+	Decision string   `json:"decision"`
+	Grade    string   `json:"risk_grade"`
+	Reasons  []string `json:"reasons"`
+	Fired    []string `json:"fired_rules"`
+}
+
+var creditRuleOperators = map[string]func(a, b float64) bool{
+	"gt":  func(a, b float64) bool { return a > b },
+	"gte": func(a, b float64) bool { return a >= b },
+	"lt":  func(a, b float64) bool { return a < b },
+	"lte": func(a, b float64) bool { return a <= b },
+	"eq":  func(a, b float64) bool { return a == b },
+	"neq": func(a, b float64) bool { return a != b },
+}
+
+// ParseCreditRules reads a rule set written in YAML or JSON, which YAML
+// accepts as a subset, and checks every rule against the known fields,
+// operators and outcomes.
+func ParseCreditRules(data []byte) (CreditRuleSet, error) {
+	var ruleSet CreditRuleSet
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return ruleSet, fmt.Errorf("invalid rule definition: %w", err)
+	}
+
+	if len(ruleSet.Rules) == 0 {
+		return ruleSet, errors.New("rule set must contain at least one rule")
+	}
+
+	names := map[string]bool{}
+	for i := range ruleSet.Rules {
+		rule := &ruleSet.Rules[i]
+		rule.Outcome = strings.ToUpper(rule.Outcome)
+
+		if rule.Name == "" {
+			return ruleSet, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return ruleSet, fmt.Errorf("rule %s is defined more than once", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if err := checkCreditCondition(&rule.CreditCondition); err != nil {
+			return ruleSet, fmt.Errorf("rule %s %w", rule.Name, err)
+		}
+		if rule.Outcome != CreditDecisionReject && rule.Outcome != CreditDecisionManualReview {
+			return ruleSet, fmt.Errorf("rule %s must have outcome REJECT or MANUAL_REVIEW", rule.Name)
+		}
+		if rule.Reason == "" {
+			rule.Reason = fmt.Sprintf("%s %s %g", rule.Field, rule.Operator, rule.Value)
+		}
+	}
+
+	for i := range ruleSet.Grades {
+		grade := &ruleSet.Grades[i]
+		grade.Grade = strings.ToUpper(grade.Grade)
+		if !ValidRiskGrade(grade.Grade) {
+			return ruleSet, fmt.Errorf("grade %d has an invalid name %q", i+1, grade.Grade)
+		}
+		for j := range grade.When {
+			if err := checkCreditCondition(&grade.When[j]); err != nil {
+				return ruleSet, fmt.Errorf("grade %s %w", grade.Grade, err)
+			}
+		}
+	}
+
+	ruleSet.DefaultGrade = strings.ToUpper(ruleSet.DefaultGrade)
+	if ruleSet.DefaultGrade != "" && !ValidRiskGrade(ruleSet.DefaultGrade) {
+		return ruleSet, fmt.Errorf("default grade %q is invalid", ruleSet.DefaultGrade)
+	}
+
+	return ruleSet, nil
+}
+
+func checkCreditCondition(condition *CreditCondition) error {
+	condition.Operator = strings.ToLower(condition.Operator)
+	if !contains(CreditFactFields, condition.Field) {
+		return fmt.Errorf("uses unknown field %q", condition.Field)
+	}
+	if _, ok := creditRuleOperators[condition.Operator]; !ok {
+		return fmt.Errorf("uses unknown operator %q", condition.Operator)
+	}
+	return nil
+}
+
+func (condition CreditCondition) holds(facts CreditFacts) bool {
+	value, ok := facts[condition.Field]
+	if !ok || math.IsNaN(value) {
+		return false
+	}
+	return creditRuleOperators[condition.Operator](value, condition.Value)
+}
+
+// ValidRiskGrade accepts short upper-case grade names such as A, B2 or LOW.
+func ValidRiskGrade(grade string) bool {
+	if grade == "" || len(grade) > 10 {
+		return false
+	}
+	for _, r := range grade {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// EvaluateCreditRules runs every rule against facts. Any rejecting rule
+// rejects the application; otherwise any review rule sends it to manual
+// review. Reasons are listed for every rule that fired, rejections first.
+func EvaluateCreditRules(ruleSet CreditRuleSet, facts CreditFacts) CreditEvaluation {
+	evaluation := CreditEvaluation{Decision: CreditDecisionApprove, Reasons: []string{}, Fired: []string{}}
+
+	var fired []CreditRule
+	for _, rule := range ruleSet.Rules {
+		if rule.holds(facts) {
+			fired = append(fired, rule)
+		}
+	}
+
+	sort.SliceStable(fired, func(i, j int) bool {
+		return fired[i].Outcome == CreditDecisionReject && fired[j].Outcome != CreditDecisionReject
+	})
+
+	for _, rule := range fired {
+		switch {
+		case rule.Outcome == CreditDecisionReject:
+			evaluation.Decision = CreditDecisionReject
+		case evaluation.Decision == CreditDecisionApprove:
+			evaluation.Decision = CreditDecisionManualReview
+		}
+		evaluation.Reasons = append(evaluation.Reasons, rule.Reason)
+		evaluation.Fired = append(evaluation.Fired, rule.Name)
+	}
+
+	evaluation.Grade = ruleSet.DefaultGrade
+	for _, grade := range ruleSet.Grades {
+		matched := true
+		for _, condition := range grade.When {
+			if !condition.holds(facts) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			evaluation.Grade = grade.Grade
+			break
+		}
+	}
+
+	return evaluation
+}
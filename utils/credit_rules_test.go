@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCreditRules = `
+name: standard
+rules:
+  - name: too_young
+    field: age
+    operator: LT
+    value: 21
+    outcome: reject
+    reason: Applicant is under 21
+  - name: written_off
+    field: written_off_loans
+    operator: gt
+    value: 0
+    outcome: REJECT
+  - name: high_burden
+    field: installment_to_income
+    operator: gt
+    value: 0.3
+    outcome: manual_review
+grades:
+  - grade: a
+    when:
+      - {field: late_installments, operator: eq, value: 0}
+      - {field: installment_to_income, operator: lte, value: 0.1}
+  - grade: B
+    when:
+      - {field: late_installments, operator: lte, value: 2}
+default_grade: c
+`
+
+func TestParseCreditRules(t *testing.T) {
+	ruleSet, err := ParseCreditRules([]byte(testCreditRules))
+	if err != nil {
+		t.Fatalf("ParseCreditRules error: %v", err)
+	}
+
+	if len(ruleSet.Rules) != 3 || len(ruleSet.Grades) != 2 {
+		t.Fatalf("got %d rules and %d grades, want 3 and 2", len(ruleSet.Rules), len(ruleSet.Grades))
+	}
+
+	first := ruleSet.Rules[0]
+	if first.Operator != "lt" || first.Outcome != CreditDecisionReject || first.Reason != "Applicant is under 21" {
+		t.Errorf("first rule not normalised: %+v", first)
+	}
+	if reason := ruleSet.Rules[1].Reason; reason != "written_off_loans gt 0" {
+		t.Errorf("default reason = %q, want %q", reason, "written_off_loans gt 0")
+	}
+	if ruleSet.Grades[0].Grade != "A" || ruleSet.DefaultGrade != "C" {
+		t.Errorf("grades not upper-cased: %q, default %q", ruleSet.Grades[0].Grade, ruleSet.DefaultGrade)
+	}
+}
+
+func TestParseCreditRulesAcceptsJSON(t *testing.T) {
+	definition := `{"name": "json", "rules": [{"name": "kyc", "field": "kyc_verified", "operator": "eq", "value": 0, "outcome": "MANUAL_REVIEW"}]}`
+	ruleSet, err := ParseCreditRules([]byte(definition))
+	if err != nil {
+		t.Fatalf("ParseCreditRules error: %v", err)
+	}
+	if ruleSet.Name != "json" || ruleSet.Rules[0].Outcome != CreditDecisionManualReview {
+		t.Errorf("unexpected rule set %+v", ruleSet)
+	}
+}
+
+func TestParseCreditRulesErrors(t *testing.T) {
+	rule := func(fields string) string {
+		return "rules:\n  - " + fields
+	}
+
+	tests := []struct {
+		name       string
+		definition string
+		want       string
+	}{
+		{"not YAML", "rules: [", "invalid rule definition"},
+		{"no rules", "name: empty", "at least one rule"},
+		{"unnamed rule", rule("{field: age, operator: lt, value: 21, outcome: REJECT}"), "rule 1 has no name"},
+		{"duplicate name", rule("{name: a, field: age, operator: lt, value: 1, outcome: REJECT}\n  - {name: a, field: age, operator: lt, value: 2, outcome: REJECT}"), "rule a is defined more than once"},
+		{"unknown field", rule("{name: a, field: shoe_size, operator: lt, value: 1, outcome: REJECT}"), `rule a uses unknown field "shoe_size"`},
+		{"unknown operator", rule("{name: a, field: age, operator: between, value: 1, outcome: REJECT}"), `rule a uses unknown operator "between"`},
+		{"approve outcome", rule("{name: a, field: age, operator: lt, value: 1, outcome: APPROVE}"), "rule a must have outcome REJECT or MANUAL_REVIEW"},
+		{"invalid grade", rule("{name: a, field: age, operator: lt, value: 1, outcome: REJECT}") + "\ngrades:\n  - {grade: a-1}", `grade 1 has an invalid name "A-1"`},
+		{"grade condition", rule("{name: a, field: age, operator: lt, value: 1, outcome: REJECT}") + "\ngrades:\n  - {grade: A, when: [{field: height, operator: gt, value: 1}]}", `grade A uses unknown field "height"`},
+		{"invalid default grade", rule("{name: a, field: age, operator: lt, value: 1, outcome: REJECT}") + "\ndefault_grade: too-long-grade", "default grade"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCreditRules([]byte(tt.definition))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEvaluateCreditRules(t *testing.T) {
+	ruleSet, err := ParseCreditRules([]byte(testCreditRules))
+	if err != nil {
+		t.Fatalf("ParseCreditRules error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		facts CreditFacts
+		want  CreditEvaluation
+	}{
+		{
+			name:  "clean application",
+			facts: CreditFacts{"age": 30, "written_off_loans": 0, "installment_to_income": 0.05, "late_installments": 0},
+			want:  CreditEvaluation{Decision: CreditDecisionApprove, Grade: "A", Reasons: []string{}, Fired: []string{}},
+		},
+		{
+			name:  "review only",
+			facts: CreditFacts{"age": 30, "written_off_loans": 0, "installment_to_income": 0.4, "late_installments": 1},
+			want:  CreditEvaluation{Decision: CreditDecisionManualReview, Grade: "B", Reasons: []string{"installment_to_income gt 0.3"}, Fired: []string{"high_burden"}},
+		},
+		{
+			name:  "rejections listed first",
+			facts: CreditFacts{"age": 19, "written_off_loans": 1, "installment_to_income": 0.4, "late_installments": 5},
+			want: CreditEvaluation{
+				Decision: CreditDecisionReject,
+				Grade:    "C",
+				Reasons:  []string{"Applicant is under 21", "written_off_loans gt 0", "installment_to_income gt 0.3"},
+				Fired:    []string{"too_young", "written_off", "high_burden"},
+			},
+		},
+		{
+			name:  "missing and undefined facts never fire",
+			facts: CreditFacts{"age": 30, "installment_to_income": math.NaN()},
+			want:  CreditEvaluation{Decision: CreditDecisionApprove, Grade: "C", Reasons: []string{}, Fired: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := EvaluateCreditRules(ruleSet, tt.facts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: evaluation = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidRiskGrade(t *testing.T) {
+	for grade, want := range map[string]bool{
+		"A": true, "B2": true, "LOW": true, "": false, "a": false, "A-1": false, "ABCDEFGHIJK": false,
+	} {
+		if got := ValidRiskGrade(grade); got != want {
+			t.Errorf("ValidRiskGrade(%q) = %v, want %v", grade, got, want)
+		}
+	}
+}
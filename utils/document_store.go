@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DocumentStore keeps uploaded files under opaque keys. Keys are relative,
+// slash-separated paths chosen by the application, never by the uploader.
+type DocumentStore interface {
+	Put(key string, content io.Reader) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalDocumentStore writes documents below Root on the local filesystem.
+type LocalDocumentStore struct {
+	Root string
+}
+
+func NewLocalDocumentStore(root string) *LocalDocumentStore {
+	return &LocalDocumentStore{Root: root}
+}
+
+func (s *LocalDocumentStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.New("invalid document key")
+	}
+	return filepath.Join(s.Root, clean), nil
+}
+
+func (s *LocalDocumentStore) Put(key string, content io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+
+	return written, nil
+}
+
+func (s *LocalDocumentStore) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalDocumentStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
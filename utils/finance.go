@@ -1,6 +1,10 @@
 package utils
 
-import "math"
+import (
+	"errors"
+	"math"
+	"time"
+)
 
 func PMT(rate float64, nper int, pv float64) float64 {
 	if rate == 0 {
@@ -20,3 +24,131 @@ func Max(a, b float64) float64 {
 	}
 	return b
 }
+
+func ValidDayCount(convention string) bool {
+	switch convention {
+	case "ACT/365", "ACT/360", "30/360":
+		return true
+	}
+	return false
+}
+
+// YearFraction returns the share of a year between two dates under the given
+// day-count convention. An empty convention keeps the original weekly basis
+// of 52 seven-day weeks a year.
+func YearFraction(from, to time.Time, convention string) float64 {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+
+	if convention == "30/360" {
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		return float64(360*(y2-y1)+30*(int(m2)-int(m1))+(d2-d1)) / 360
+	}
+
+	days := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24
+
+	switch convention {
+	case "ACT/365":
+		return days / 365
+	case "ACT/360":
+		return days / 360
+	default:
+		return days / 364
+	}
+}
+
+// LevelPayment returns the equal payment that repays principal over periods
+// whose interest rates may differ, such as periods of unequal length.
+func LevelPayment(principal float64, rates []float64) float64 {
+	if len(rates) == 0 {
+		return 0
+	}
+
+	growth := 1.0
+	annuity := 0.0
+	for i := len(rates) - 1; i >= 0; i-- {
+		annuity += growth
+		growth *= 1 + rates[i]
+	}
+	return principal * growth / annuity
+}
+
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// EffectiveAnnualRate solves for the annual rate r at which the dated cash
+// flows have zero present value, discounting each by (1+r)^(days/365) from
+// the first flow. Newton's method is tried first, with bisection as the
+// fallback when it does not converge.
+func EffectiveAnnualRate(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, errors.New("at least two cash flows are required")
+	}
+
+	years := make([]float64, len(flows))
+	for i, flow := range flows {
+		years[i] = flow.Date.Sub(flows[0].Date).Hours() / 24 / 365
+	}
+
+	npv := func(rate float64) float64 {
+		var total float64
+		for i, flow := range flows {
+			total += flow.Amount / math.Pow(1+rate, years[i])
+		}
+		return total
+	}
+
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		value := npv(rate)
+		var slope float64
+		for j, flow := range flows {
+			slope -= years[j] * flow.Amount / math.Pow(1+rate, years[j]+1)
+		}
+		if slope == 0 {
+			break
+		}
+		next := rate - value/slope
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-10 {
+			return next, nil
+		}
+		rate = next
+	}
+
+	low, high := -0.9999, 1000.0
+	if npv(low)*npv(high) > 0 {
+		return 0, errors.New("cash flows have no rate of return")
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if npv(low)*npv(mid) <= 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return (low + high) / 2, nil
+}
+
+// NominalAnnualRate converts an effective annual rate to the nominal rate
+// compounded periodsPerYear times a year.
+func NominalAnnualRate(effectiveRate float64, periodsPerYear int) float64 {
+	return float64(periodsPerYear) * (math.Pow(1+effectiveRate, 1/float64(periodsPerYear)) - 1)
+}
+
+func Min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
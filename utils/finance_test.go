@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestYearFraction(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		from, to   time.Time
+		convention string
+		want       float64
+	}{
+		{"ACT/365 full year", date(2023, 1, 1), date(2024, 1, 1), "ACT/365", 1},
+		{"ACT/365 leap year", date(2024, 1, 1), date(2025, 1, 1), "ACT/365", 366.0 / 365},
+		{"ACT/360 quarter", date(2023, 1, 1), date(2023, 4, 1), "ACT/360", 90.0 / 360},
+		{"30/360 month ends", date(2023, 1, 31), date(2023, 3, 31), "30/360", 60.0 / 360},
+		{"30/360 end of February", date(2023, 1, 30), date(2023, 2, 28), "30/360", 28.0 / 360},
+		{"30/360 into the 31st", date(2023, 1, 15), date(2023, 3, 31), "30/360", 76.0 / 360},
+		{"weekly basis", date(2023, 1, 2), date(2023, 1, 9), "", 1.0 / 52},
+		{"ignores time of day", time.Date(2023, 1, 1, 23, 0, 0, 0, time.UTC), date(2023, 1, 8), "ACT/365", 7.0 / 365},
+	}
+
+	for _, tt := range tests {
+		if got := YearFraction(tt.from, tt.to, tt.convention); !almostEqual(got, tt.want, 1e-12) {
+			t.Errorf("%s: YearFraction = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevelPaymentMatchesPMTForEqualRates(t *testing.T) {
+	for _, rate := range []float64{0, 0.002, 0.01} {
+		rates := make([]float64, 12)
+		for i := range rates {
+			rates[i] = rate
+		}
+		if got, want := LevelPayment(1000, rates), PMT(rate, 12, 1000); !almostEqual(got, want, 1e-9) {
+			t.Errorf("rate %v: LevelPayment = %v, PMT = %v", rate, got, want)
+		}
+	}
+}
+
+func TestLevelPaymentAmortisesUnevenPeriods(t *testing.T) {
+	rates := []float64{0.01, 0.02, 0.005, 0.015}
+	payment := LevelPayment(1000, rates)
+
+	balance := 1000.0
+	for _, rate := range rates {
+		balance = balance*(1+rate) - payment
+	}
+	if !almostEqual(balance, 0, 1e-9) {
+		t.Errorf("balance after last payment = %v, want 0", balance)
+	}
+
+	if got := LevelPayment(1000, nil); got != 0 {
+		t.Errorf("LevelPayment with no periods = %v, want 0", got)
+	}
+}
+
+func weeklyFlows(principal, payment float64, weeks int) []CashFlow {
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	flows := []CashFlow{{Date: start, Amount: -principal}}
+	for i := 1; i <= weeks; i++ {
+		flows = append(flows, CashFlow{Date: start.AddDate(0, 0, 7*i), Amount: payment})
+	}
+	return flows
+}
+
+func TestEffectiveAnnualRate(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+	}{
+		{"zero interest", weeklyFlows(1000, 250, 4), 0},
+		{
+			"fee only, one year",
+			[]CashFlow{{Date: start, Amount: -1000}, {Date: start.AddDate(1, 0, 0), Amount: 1100}},
+			0.10,
+		},
+		{"0.2% a week for 52 weeks", weeklyFlows(1000, PMT(0.002, 52, 1000), 52), 0.1098019405},
+	}
+
+	for _, tt := range tests {
+		got, err := EffectiveAnnualRate(tt.flows)
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		if !almostEqual(got, tt.want, 1e-8) {
+			t.Errorf("%s: EffectiveAnnualRate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveAnnualRateErrors(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := EffectiveAnnualRate([]CashFlow{{Date: start, Amount: -1000}}); err == nil {
+		t.Error("expected an error for a single cash flow")
+	}
+
+	allPositive := []CashFlow{{Date: start, Amount: 1000}, {Date: start.AddDate(0, 1, 0), Amount: 1000}}
+	if _, err := EffectiveAnnualRate(allPositive); err == nil {
+		t.Error("expected an error for flows with no sign change")
+	}
+}
+
+func TestNominalAnnualRate(t *testing.T) {
+	tests := []struct {
+		effective float64
+		periods   int
+		want      float64
+	}{
+		{0, 52, 0},
+		{0.10, 1, 0.10},
+		{0.10, 52, 0.0953975796},
+		{math.Pow(1.01, 12) - 1, 12, 0.12},
+	}
+
+	for _, tt := range tests {
+		if got := NominalAnnualRate(tt.effective, tt.periods); !almostEqual(got, tt.want, 1e-9) {
+			t.Errorf("NominalAnnualRate(%v, %d) = %v, want %v", tt.effective, tt.periods, got, tt.want)
+		}
+	}
+}
@@ -19,6 +19,17 @@ func GenerateJWT(userID uint64) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
+func GenerateMFAToken(userID uint64) (string, error) {
+	claims := jwt.MapClaims{
+		"mfa_user_id": userID,
+		"exp":         time.Now().Add(time.Minute * 5).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(jwtSecret)
+}
+
 func ValidateJWT(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
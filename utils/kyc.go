@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var EmploymentStatuses = []string{"EMPLOYED", "SELF_EMPLOYED", "UNEMPLOYED", "STUDENT", "RETIRED"}
+
+var KYCDocumentTypes = []string{"KTP", "SELFIE", "PAYSLIP", "BANK_STATEMENT"}
+
+// RequiredKYCDocuments must each have a verified upload before a borrower's
+// KYC can be verified.
+var RequiredKYCDocuments = []string{"KTP", "SELFIE"}
+
+func ValidEmploymentStatus(status string) bool {
+	return contains(EmploymentStatuses, status)
+}
+
+func ValidKYCDocumentType(documentType string) bool {
+	return contains(KYCDocumentTypes, documentType)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateNIK checks an Indonesian national ID number. It is 16 digits:
+// province, regency and district codes, the holder's birth date as DDMMYY
+// with 40 added to the day for women, and a four-digit serial. When dob is
+// set the embedded birth date must match it.
+func ValidateNIK(nik string, dob time.Time) error {
+	if len(nik) != 16 {
+		return errors.New("NIK must be 16 digits")
+	}
+	for _, r := range nik {
+		if r < '0' || r > '9' {
+			return errors.New("NIK must contain digits only")
+		}
+	}
+
+	if province, _ := strconv.Atoi(nik[0:2]); province < 11 || province > 99 {
+		return errors.New("NIK has an invalid province code")
+	}
+	if nik[2:4] == "00" || nik[4:6] == "00" {
+		return errors.New("NIK has an invalid region code")
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	if day > 40 {
+		day -= 40
+	}
+	month, _ := strconv.Atoi(nik[8:10])
+	year, _ := strconv.Atoi(nik[10:12])
+	if day < 1 || day > 31 || month < 1 || month > 12 {
+		return errors.New("NIK has an invalid birth date")
+	}
+
+	if nik[12:16] == "0000" {
+		return errors.New("NIK has an invalid serial number")
+	}
+
+	if !dob.IsZero() && (dob.Day() != day || int(dob.Month()) != month || dob.Year()%100 != year) {
+		return errors.New("NIK does not match date of birth")
+	}
+
+	return nil
+}
+
+// NormalizePhoneNumber accepts Indonesian mobile numbers written as 08...,
+// 628... or +628... and returns them as +628....
+func NormalizePhoneNumber(phone string) (string, error) {
+	phone = strings.NewReplacer(" ", "", "-", "").Replace(phone)
+
+	switch {
+	case strings.HasPrefix(phone, "+62"):
+		phone = phone[3:]
+	case strings.HasPrefix(phone, "62"):
+		phone = phone[2:]
+	case strings.HasPrefix(phone, "0"):
+		phone = phone[1:]
+	default:
+		return "", errors.New("phone number must start with 0, 62 or +62")
+	}
+
+	if !strings.HasPrefix(phone, "8") || len(phone) < 9 || len(phone) > 12 {
+		return "", errors.New("phone number is not a valid mobile number")
+	}
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", errors.New("phone number must contain digits only")
+		}
+	}
+
+	return "+62" + phone, nil
+}
+
+func GenerateDocumentKey(userID uint64, documentType, extension string) string {
+	return fmt.Sprintf("kyc/%d/%s-%d%s", userID, strings.ToLower(documentType), time.Now().UnixNano(), extension)
+}
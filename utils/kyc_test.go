@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateNIK(t *testing.T) {
+	dob := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		nik     string
+		dob     time.Time
+		wantErr string
+	}{
+		{"valid, no date of birth", "3174051705900001", time.Time{}, ""},
+		{"valid, matching date of birth", "3174051705900001", dob, ""},
+		{"valid, woman's day offset", "3174055705900001", dob, ""},
+		{"too short", "317405170590001", time.Time{}, "NIK must be 16 digits"},
+		{"too long", "31740517059000011", time.Time{}, "NIK must be 16 digits"},
+		{"non-digit", "31740517059O0001", time.Time{}, "NIK must contain digits only"},
+		{"province below range", "1074051705900001", time.Time{}, "NIK has an invalid province code"},
+		{"zero regency", "3100051705900001", time.Time{}, "NIK has an invalid region code"},
+		{"zero district", "3174001705900001", time.Time{}, "NIK has an invalid region code"},
+		{"day zero", "3174050005900001", time.Time{}, "NIK has an invalid birth date"},
+		{"day 72 after offset", "3174057205900001", time.Time{}, "NIK has an invalid birth date"},
+		{"month 13", "3174051713900001", time.Time{}, "NIK has an invalid birth date"},
+		{"zero serial", "3174051705900000", time.Time{}, "NIK has an invalid serial number"},
+		{"date of birth mismatch", "3174051805900001", dob, "NIK does not match date of birth"},
+		{"birth year mismatch", "3174051705910001", dob, "NIK does not match date of birth"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateNIK(tt.nik, tt.dob)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"081234567890", "+6281234567890", false},
+		{"6281234567890", "+6281234567890", false},
+		{"+62 812-3456-7890", "+6281234567890", false},
+		{"0812345678", "+62812345678", false},
+		{"0212345678", "", true},
+		{"81234567890", "", true},
+		{"08123456", "", true},
+		{"0812345678901234", "", true},
+		{"0812345678a", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhoneNumber(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizePhoneNumber(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizePhoneNumber(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -21,3 +21,19 @@ func GeneratePaymentCode() string {
 
 	return fmt.Sprintf("PAY-%s-%d", timePart, randomPart)
 }
+
+func GenerateRecoveryCode() string {
+	now := time.Now()
+	timePart := now.Format("20060102")
+	randomPart := rand.Intn(900) + 100
+
+	return fmt.Sprintf("REC-%s-%d", timePart, randomPart)
+}
+
+func GenerateCreditLineCode() string {
+	now := time.Now()
+	timePart := now.Format("20060102")
+	randomPart := rand.Intn(900) + 100
+
+	return fmt.Sprintf("CL-%s-%d", timePart, randomPart)
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+type LoginAttemptRecord struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+type LoginAttemptStore interface {
+	Get(key string) (LoginAttemptRecord, error)
+	RecordFailure(key string, now time.Time, window time.Duration) (LoginAttemptRecord, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+type LoginThrottlePolicy struct {
+	FreeFailures    int
+	MaxFailures     int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	Window          time.Duration
+	LockoutDuration time.Duration
+}
+
+var AccountLoginPolicy = LoginThrottlePolicy{
+	FreeFailures:    2,
+	MaxFailures:     5,
+	BaseDelay:       time.Second,
+	MaxDelay:        30 * time.Second,
+	Window:          15 * time.Minute,
+	LockoutDuration: 15 * time.Minute,
+}
+
+var IPLoginPolicy = LoginThrottlePolicy{
+	FreeFailures:    5,
+	MaxFailures:     20,
+	BaseDelay:       time.Second,
+	MaxDelay:        time.Minute,
+	Window:          15 * time.Minute,
+	LockoutDuration: 30 * time.Minute,
+}
+
+// LoginRetryAfter reports how long the caller must wait before another login
+// attempt is accepted for the given record, or zero if it may proceed now.
+func LoginRetryAfter(record LoginAttemptRecord, policy LoginThrottlePolicy, now time.Time) time.Duration {
+	if now.Before(record.LockedUntil) {
+		return record.LockedUntil.Sub(now)
+	}
+
+	if record.Failures == 0 || now.Sub(record.LastFailureAt) > policy.Window {
+		return 0
+	}
+
+	nextAllowed := record.LastFailureAt.Add(LoginDelay(record.Failures, policy))
+	if now.Before(nextAllowed) {
+		return nextAllowed.Sub(now)
+	}
+	return 0
+}
+
+// LoginDelay doubles the wait for every failure past the free allowance.
+func LoginDelay(failures int, policy LoginThrottlePolicy) time.Duration {
+	extra := failures - policy.FreeFailures
+	if extra <= 0 {
+		return 0
+	}
+
+	delay := policy.BaseDelay
+	for i := 1; i < extra; i++ {
+		delay *= 2
+		if delay >= policy.MaxDelay {
+			return policy.MaxDelay
+		}
+	}
+	return delay
+}
+
+func ShouldLockLogin(record LoginAttemptRecord, policy LoginThrottlePolicy) bool {
+	return record.Failures >= policy.MaxFailures
+}
+
+type MemoryLoginAttemptStore struct {
+	mu      sync.Mutex
+	records map[string]LoginAttemptRecord
+}
+
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{records: make(map[string]LoginAttemptRecord)}
+}
+
+func (s *MemoryLoginAttemptStore) Get(key string) (LoginAttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return LoginAttemptRecord{Key: key}, nil
+	}
+	return record, nil
+}
+
+func (s *MemoryLoginAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (LoginAttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || now.Sub(record.LastFailureAt) > window {
+		record = LoginAttemptRecord{Key: key, LockedUntil: record.LockedUntil}
+	}
+
+	record.Failures++
+	record.LastFailureAt = now
+	s.records[key] = record
+
+	return record, nil
+}
+
+func (s *MemoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Key = key
+	record.Failures = 0
+	record.LockedUntil = until
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"go-billing-engine/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostgresLoginAttemptStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresLoginAttemptStore(db *gorm.DB) *PostgresLoginAttemptStore {
+	return &PostgresLoginAttemptStore{db: db}
+}
+
+func (s *PostgresLoginAttemptStore) Get(key string) (LoginAttemptRecord, error) {
+	var attempt models.LoginAttempt
+	err := s.db.Where("attempt_key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return LoginAttemptRecord{Key: key}, nil
+	}
+	if err != nil {
+		return LoginAttemptRecord{}, err
+	}
+	return toLoginAttemptRecord(attempt), nil
+}
+
+func (s *PostgresLoginAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (LoginAttemptRecord, error) {
+	var attempt models.LoginAttempt
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockLoginAttempt(tx, key, now, &attempt); err != nil {
+			return err
+		}
+
+		if now.Sub(attempt.LastFailureAt) > window {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		attempt.UpdatedAt = now
+
+		return tx.Save(&attempt).Error
+	})
+	if err != nil {
+		return LoginAttemptRecord{}, err
+	}
+
+	return toLoginAttemptRecord(attempt), nil
+}
+
+func (s *PostgresLoginAttemptStore) Lock(key string, until time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var attempt models.LoginAttempt
+		if err := lockLoginAttempt(tx, key, now, &attempt); err != nil {
+			return err
+		}
+
+		attempt.Failures = 0
+		attempt.LockedUntil = until
+		attempt.UpdatedAt = now
+
+		return tx.Save(&attempt).Error
+	})
+}
+
+func (s *PostgresLoginAttemptStore) Reset(key string) error {
+	return s.db.Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// lockLoginAttempt makes sure a row exists for key and loads it with a row
+// lock so concurrent failures for the same key are counted one at a time.
+func lockLoginAttempt(tx *gorm.DB, key string, now time.Time, attempt *models.LoginAttempt) error {
+	seed := models.LoginAttempt{
+		AttemptKey: key,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "attempt_key"}},
+		DoNothing: true,
+	}).Create(&seed).Error; err != nil {
+		return err
+	}
+
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("attempt_key = ?", key).
+		First(attempt).Error
+}
+
+func toLoginAttemptRecord(attempt models.LoginAttempt) LoginAttemptRecord {
+	return LoginAttemptRecord{
+		Key:           attempt.AttemptKey,
+		Failures:      attempt.Failures,
+		LastFailureAt: attempt.LastFailureAt,
+		LockedUntil:   attempt.LockedUntil,
+	}
+}
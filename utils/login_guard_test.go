@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		policy   LoginThrottlePolicy
+		want     time.Duration
+	}{
+		{0, AccountLoginPolicy, 0},
+		{2, AccountLoginPolicy, 0},
+		{3, AccountLoginPolicy, time.Second},
+		{4, AccountLoginPolicy, 2 * time.Second},
+		{5, AccountLoginPolicy, 4 * time.Second},
+		{7, AccountLoginPolicy, 16 * time.Second},
+		{8, AccountLoginPolicy, 30 * time.Second},
+		{50, AccountLoginPolicy, 30 * time.Second},
+		{5, IPLoginPolicy, 0},
+		{6, IPLoginPolicy, time.Second},
+		{12, IPLoginPolicy, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := LoginDelay(tt.failures, tt.policy); got != tt.want {
+			t.Errorf("LoginDelay(%d, free %d) = %v, want %v", tt.failures, tt.policy.FreeFailures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		record LoginAttemptRecord
+		want   time.Duration
+	}{
+		{"no failures", LoginAttemptRecord{}, 0},
+		{"free failures", LoginAttemptRecord{Failures: 2, LastFailureAt: now}, 0},
+		{"backoff pending", LoginAttemptRecord{Failures: 3, LastFailureAt: now.Add(-300 * time.Millisecond)}, 700 * time.Millisecond},
+		{"backoff elapsed", LoginAttemptRecord{Failures: 3, LastFailureAt: now.Add(-2 * time.Second)}, 0},
+		{"doubled backoff", LoginAttemptRecord{Failures: 5, LastFailureAt: now.Add(-time.Second)}, 3 * time.Second},
+		{"failures outside the window", LoginAttemptRecord{Failures: 8, LastFailureAt: now.Add(-16 * time.Minute)}, 0},
+		{"locked", LoginAttemptRecord{LockedUntil: now.Add(5 * time.Minute)}, 5 * time.Minute},
+		{"lock wins over backoff", LoginAttemptRecord{Failures: 3, LastFailureAt: now, LockedUntil: now.Add(time.Minute)}, time.Minute},
+		{"lock expired", LoginAttemptRecord{LockedUntil: now}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := LoginRetryAfter(tt.record, AccountLoginPolicy, now); got != tt.want {
+			t.Errorf("%s: LoginRetryAfter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestShouldLockLogin(t *testing.T) {
+	tests := []struct {
+		failures int
+		policy   LoginThrottlePolicy
+		want     bool
+	}{
+		{4, AccountLoginPolicy, false},
+		{5, AccountLoginPolicy, true},
+		{6, AccountLoginPolicy, true},
+		{19, IPLoginPolicy, false},
+		{20, IPLoginPolicy, true},
+	}
+
+	for _, tt := range tests {
+		if got := ShouldLockLogin(LoginAttemptRecord{Failures: tt.failures}, tt.policy); got != tt.want {
+			t.Errorf("ShouldLockLogin(%d, max %d) = %v, want %v", tt.failures, tt.policy.MaxFailures, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryLoginAttemptStore(t *testing.T) {
+	policy := AccountLoginPolicy
+	store := NewMemoryLoginAttemptStore()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var record LoginAttemptRecord
+	var err error
+	for i := 0; i < policy.MaxFailures; i++ {
+		if record, err = store.RecordFailure("account:a", start.Add(time.Duration(i)*time.Second), policy.Window); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if record.Failures != policy.MaxFailures || !ShouldLockLogin(record, policy) {
+		t.Fatalf("after %d failures record = %+v, want a lockout", policy.MaxFailures, record)
+	}
+
+	lockedAt := record.LastFailureAt
+	if err := store.Lock("account:a", lockedAt.Add(policy.LockoutDuration)); err != nil {
+		t.Fatal(err)
+	}
+
+	record, _ = store.Get("account:a")
+	if record.Failures != 0 {
+		t.Errorf("Failures after Lock = %d, want 0", record.Failures)
+	}
+	if got := LoginRetryAfter(record, policy, lockedAt.Add(10*time.Minute)); got != 5*time.Minute {
+		t.Errorf("retry during lockout = %v, want 5m", got)
+	}
+	if got := LoginRetryAfter(record, policy, lockedAt.Add(policy.LockoutDuration)); got != 0 {
+		t.Errorf("retry when lockout expires = %v, want 0", got)
+	}
+
+	// A failure long after the last one starts a new count but keeps the lock.
+	later := lockedAt.Add(policy.Window + time.Minute)
+	if err := store.Lock("account:a", later.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RecordFailure("account:a", later, policy.Window); err != nil {
+		t.Fatal(err)
+	}
+	record, _ = store.Get("account:a")
+	if record.Failures != 1 || !record.LockedUntil.Equal(later.Add(time.Hour)) {
+		t.Errorf("record after window = %+v, want 1 failure and the lock kept", record)
+	}
+
+	// Failures spread wider than the window never add up to a lockout.
+	for i := 0; i < 2*policy.MaxFailures; i++ {
+		at := later.Add(time.Duration(i+1) * (policy.Window + time.Second))
+		if record, err = store.RecordFailure("account:b", at, policy.Window); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if record.Failures != 1 {
+		t.Errorf("spread failures = %d, want 1", record.Failures)
+	}
+
+	if err := store.Reset("account:a"); err != nil {
+		t.Fatal(err)
+	}
+	record, _ = store.Get("account:a")
+	if record.Failures != 0 || !record.LockedUntil.IsZero() {
+		t.Errorf("record after Reset = %+v, want empty", record)
+	}
+}
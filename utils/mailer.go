@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(message MailMessage) error
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(message MailMessage) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	headers := []string{
+		"From: " + m.From,
+		"To: " + message.To,
+		"Subject: " + message.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{message.To}, []byte(body))
+}
+
+// LogMailer writes outgoing mail to a file, or to the standard logger when no
+// path is configured, so links can be followed during local development.
+type LogMailer struct {
+	mu   sync.Mutex
+	Path string
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{Path: path}
+}
+
+func (m *LogMailer) Send(message MailMessage) error {
+	entry := fmt.Sprintf("[%s] To: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+
+	if m.Path == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(entry)
+	return err
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+)
+
+const (
+	PrincipalTypeUser      = "USER"
+	PrincipalTypeAPIClient = "API_CLIENT"
+
+	apiKeyPrefix = "gbe"
+)
+
+var APIScopes = []string{
+	"loans:read",
+	"loans:write",
+	"payments:write",
+}
+
+func ValidAPIScope(scope string) bool {
+	for _, s := range APIScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type Principal struct {
+	Type     string   `json:"type"`
+	UserID   uint64   `json:"user_id,omitempty"`
+	ClientID uint64   `json:"client_id,omitempty"`
+	Role     string   `json:"role,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+func (p Principal) IsUser() bool {
+	return p.Type == PrincipalTypeUser
+}
+
+func (p Principal) IsAPIClient() bool {
+	return p.Type == PrincipalTypeAPIClient
+}
+
+// HasScope reports whether the principal may use an endpoint guarded by scope.
+// Users are governed by their role rather than scopes, so they always pass.
+func (p Principal) HasScope(scope string) bool {
+	if p.IsUser() {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey returns a key of the form gbe_<prefix>_<secret>. The prefix
+// is stored in clear text for lookup; only a hash of the full key is kept.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	prefixBytes := make([]byte, 6)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+
+	secretBytes := make([]byte, 24)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyPrefix + "_" + prefix + "_" + hex.EncodeToString(secretBytes)
+	return key, prefix, nil
+}
+
+func ParseAPIKeyPrefix(key string) (string, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", errors.New("malformed API key")
+	}
+	return parts[1], nil
+}
+
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// IPAllowed checks ip against a list of single addresses and CIDR ranges. An
+// empty list allows every address.
+func IPAllowed(ip string, allowList []string) bool {
+	if len(allowList) == 0 {
+		return true
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, entry := range allowList {
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err == nil && network.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package utils
+
+import "time"
+
+// ScheduleTerms describes an amortizing schedule. Interest for each period is
+// AnnualRate times the year fraction between due dates under DayCount.
+// GracePeriods delays the first due date by that many weeks with nothing to
+// pay; the interest accrued over them is capitalized into the principal, or
+// added to the first installment when GraceInterestMode is ACCRUED. The first
+// InterestOnlyPeriods of the Periods installments pay interest only. Due dates
+// that fall on a non-business day of Calendar are moved according to
+// DateAdjustment.
+type ScheduleTerms struct {
+	Principal           float64
+	AnnualRate          float64
+	DayCount            string
+	Periods             int
+	GracePeriods        int
+	GraceInterestMode   string
+	InterestOnlyPeriods int
+	StartDate           time.Time
+	Calendar            *BusinessCalendar
+	DateAdjustment      string
+}
+
+type ScheduleLine struct {
+	Sequence          int
+	InstallmentAmount float64
+	InterestAmount    float64
+	PrincipalAmount   float64
+	OutstandingAmount float64
+	DueDate           time.Time
+}
+
+// BuildWeeklySchedule returns the installments for terms, due one week apart
+// starting one week after terms.StartDate plus any grace periods.
+func BuildWeeklySchedule(terms ScheduleTerms) []ScheduleLine {
+	balance := terms.Principal
+	var accruedInterest float64
+	graceStart := terms.StartDate
+	for i := 0; i < terms.GracePeriods; i++ {
+		graceEnd := graceStart.AddDate(0, 0, 7)
+		interest := balance * terms.AnnualRate * YearFraction(graceStart, graceEnd, terms.DayCount)
+		if terms.GraceInterestMode == "ACCRUED" {
+			accruedInterest += interest
+		} else {
+			balance += interest
+		}
+		graceStart = graceEnd
+	}
+
+	baseDate := graceStart.AddDate(0, 0, 7)
+	dueDates := make([]time.Time, terms.Periods)
+	rates := make([]float64, terms.Periods)
+	periodStart := graceStart
+	for i := range dueDates {
+		dueDates[i] = terms.Calendar.AdjustDate(baseDate.AddDate(0, 0, i*7), terms.DateAdjustment)
+		rates[i] = terms.AnnualRate * YearFraction(periodStart, dueDates[i], terms.DayCount)
+		periodStart = dueDates[i]
+	}
+
+	interestOnly := terms.InterestOnlyPeriods
+	if interestOnly >= terms.Periods {
+		interestOnly = terms.Periods - 1
+	}
+
+	pmt := LevelPayment(balance, rates[interestOnly:])
+
+	lines := make([]ScheduleLine, 0, terms.Periods)
+	for i := 0; i < terms.Periods; i++ {
+		interest := balance * rates[i]
+		principal := pmt - interest
+		if i < interestOnly {
+			principal = 0
+		}
+		balance -= principal
+
+		if i == 0 {
+			interest += accruedInterest
+		}
+
+		lines = append(lines, ScheduleLine{
+			Sequence:          i + 1,
+			InstallmentAmount: principal + interest,
+			InterestAmount:    interest,
+			PrincipalAmount:   principal,
+			OutstandingAmount: Max(balance, 0),
+			DueDate:           dueDates[i],
+		})
+	}
+
+	return lines
+}
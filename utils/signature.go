@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignRequest computes the HMAC-SHA256 signature partners send with callbacks:
+// the method, path, timestamp and hex SHA-256 of the body joined by newlines.
+func SignRequest(secret, method, path, timestamp string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifyRequestSignature(secret, method, path, timestamp string, body []byte, signature string) bool {
+	expected := SignRequest(secret, method, path, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyRequestSignature(t *testing.T) {
+	const (
+		secret    = "partner-secret"
+		path      = "/callbacks/payments"
+		timestamp = "1700000000"
+	)
+	body := []byte(`{"loan_code":"LOA-20240101-001","amount":125000}`)
+	signature := SignRequest(secret, "POST", path, timestamp, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		method    string
+		path      string
+		timestamp string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"valid", secret, "POST", path, timestamp, body, signature, true},
+		{"method case and upper-case hex", secret, "post", path, timestamp, body, strings.ToUpper(signature), true},
+		{"tampered body", secret, "POST", path, timestamp, []byte(`{"loan_code":"LOA-20240101-001","amount":925000}`), signature, false},
+		{"empty body", secret, "POST", path, timestamp, nil, signature, false},
+		{"other path", secret, "POST", "/callbacks/refunds", timestamp, body, signature, false},
+		{"other method", secret, "PUT", path, timestamp, body, signature, false},
+		{"re-dated timestamp", secret, "POST", path, "1700000300", body, signature, false},
+		{"wrong secret", "other-secret", "POST", path, timestamp, body, signature, false},
+		{"truncated signature", secret, "POST", path, timestamp, body, signature[:len(signature)-2], false},
+		{"empty signature", secret, "POST", path, timestamp, body, "", false},
+	}
+
+	for _, tt := range tests {
+		if got := VerifyRequestSignature(tt.secret, tt.method, tt.path, tt.timestamp, tt.body, tt.signature); got != tt.want {
+			t.Errorf("%s: VerifyRequestSignature() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
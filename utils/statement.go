@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StatementLine struct {
+	LineNumber  int
+	ValueDate   time.Time
+	Direction   string
+	Amount      float64
+	Reference   string
+	Description string
+}
+
+type ParsedStatement struct {
+	AccountNumber string
+	Lines         []StatementLine
+}
+
+var statementDateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006", "20060102"}
+
+// ParseCSVStatement reads a statement with a header row. The date, amount and
+// reference columns are required; description and type (CR/DR) are optional.
+// Without a type column a negative amount is read as a debit.
+func ParseCSVStatement(r io.Reader) (ParsedStatement, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return ParsedStatement{}, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "amount", "reference"} {
+		if _, ok := columns[required]; !ok {
+			return ParsedStatement{}, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var statement ParsedStatement
+	lineNumber := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ParsedStatement{}, fmt.Errorf("line %d: %w", lineNumber+1, err)
+		}
+		lineNumber++
+
+		if statement.AccountNumber == "" {
+			statement.AccountNumber = field(record, "account")
+		}
+
+		valueDate, err := parseStatementDate(field(record, "date"))
+		if err != nil {
+			return ParsedStatement{}, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(field(record, "amount"), ",", ""), 64)
+		if err != nil {
+			return ParsedStatement{}, fmt.Errorf("line %d: invalid amount", lineNumber)
+		}
+
+		direction := "CREDIT"
+		switch strings.ToUpper(field(record, "type")) {
+		case "DR", "D", "DEBIT":
+			direction = "DEBIT"
+		case "CR", "C", "CREDIT":
+		default:
+			if amount < 0 {
+				direction = "DEBIT"
+			}
+		}
+		if amount < 0 {
+			amount = -amount
+		}
+
+		statement.Lines = append(statement.Lines, StatementLine{
+			LineNumber:  lineNumber,
+			ValueDate:   valueDate,
+			Direction:   direction,
+			Amount:      amount,
+			Reference:   field(record, "reference"),
+			Description: field(record, "description"),
+		})
+	}
+
+	return statement, nil
+}
+
+var mt940TransactionPattern = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])[A-Z]?([\d,]+)[A-Z][A-Z0-9]{3}([^/]*)(?://(.*))?$`)
+
+// ParseMT940Statement reads the :25: account tag, each :61: statement line and
+// the :86: information that follows it. Reversals (RC/RD) flip the direction.
+func ParseMT940Statement(r io.Reader) (ParsedStatement, error) {
+	scanner := bufio.NewScanner(r)
+
+	var statement ParsedStatement
+	var current *StatementLine
+	var currentTag string
+	lineNumber := 0
+
+	flush := func() {
+		if current != nil {
+			current.Description = strings.TrimSpace(current.Description)
+			statement.Lines = append(statement.Lines, *current)
+			current = nil
+		}
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if !strings.HasPrefix(text, ":") {
+			if currentTag == "86" && current != nil {
+				current.Description += " " + strings.TrimSpace(text)
+			}
+			continue
+		}
+
+		end := strings.Index(text[1:], ":")
+		if end < 0 {
+			continue
+		}
+		currentTag = text[1 : end+1]
+		value := text[end+2:]
+
+		switch currentTag {
+		case "25":
+			statement.AccountNumber = strings.TrimSpace(value)
+		case "61":
+			flush()
+
+			match := mt940TransactionPattern.FindStringSubmatch(strings.TrimSpace(value))
+			if match == nil {
+				return ParsedStatement{}, fmt.Errorf("line %d: invalid :61: statement line", lineNumber)
+			}
+
+			valueDate, err := time.Parse("060102", match[1])
+			if err != nil {
+				return ParsedStatement{}, fmt.Errorf("line %d: invalid value date", lineNumber)
+			}
+
+			amount, err := strconv.ParseFloat(strings.Replace(match[4], ",", ".", 1), 64)
+			if err != nil {
+				return ParsedStatement{}, fmt.Errorf("line %d: invalid amount", lineNumber)
+			}
+
+			direction := "CREDIT"
+			if match[3] == "D" || match[3] == "RC" {
+				direction = "DEBIT"
+			}
+
+			reference := strings.TrimSpace(match[5])
+			if reference == "" || reference == "NONREF" {
+				reference = strings.TrimSpace(match[6])
+			}
+
+			current = &StatementLine{
+				LineNumber: lineNumber,
+				ValueDate:  valueDate,
+				Direction:  direction,
+				Amount:     amount,
+				Reference:  reference,
+			}
+		case "86":
+			if current != nil {
+				current.Description = strings.TrimSpace(value)
+			}
+		case "62F", "62M":
+			flush()
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return ParsedStatement{}, err
+	}
+
+	return statement, nil
+}
+
+func parseStatementDate(value string) (time.Time, error) {
+	for _, layout := range statementDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
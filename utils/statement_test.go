@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSVStatement(t *testing.T) {
+	input := strings.Join([]string{
+		"Date,Amount,Reference,Description,Type,Account",
+		"2023-10-19,\"1,500.00\",LOAN-001,Transfer in,CR,1234567890",
+		"20/10/2023,250,LOAN-002,Reversal,DR,",
+		"20231021,-75.5,FEE-1,,,",
+		"2023-10-22,100,LOAN-003,,,",
+	}, "\n")
+
+	statement, err := ParseCSVStatement(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseCSVStatement error: %v", err)
+	}
+
+	if statement.AccountNumber != "1234567890" {
+		t.Errorf("AccountNumber = %q, want 1234567890", statement.AccountNumber)
+	}
+
+	want := []StatementLine{
+		{LineNumber: 2, ValueDate: time.Date(2023, 10, 19, 0, 0, 0, 0, time.UTC), Direction: "CREDIT", Amount: 1500, Reference: "LOAN-001", Description: "Transfer in"},
+		{LineNumber: 3, ValueDate: time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC), Direction: "DEBIT", Amount: 250, Reference: "LOAN-002", Description: "Reversal"},
+		{LineNumber: 4, ValueDate: time.Date(2023, 10, 21, 0, 0, 0, 0, time.UTC), Direction: "DEBIT", Amount: 75.5, Reference: "FEE-1"},
+		{LineNumber: 5, ValueDate: time.Date(2023, 10, 22, 0, 0, 0, 0, time.UTC), Direction: "CREDIT", Amount: 100, Reference: "LOAN-003"},
+	}
+	if len(statement.Lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(statement.Lines), len(want))
+	}
+	for i, line := range statement.Lines {
+		if line != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, line, want[i])
+		}
+	}
+}
+
+func TestParseCSVStatementErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty file", "", "failed to read header"},
+		{"missing reference column", "date,amount\n2023-10-19,10", `missing required column "reference"`},
+		{"bad date", "date,amount,reference\n19 Oct 2023,10,REF", "line 2: invalid date"},
+		{"bad amount", "date,amount,reference\n2023-10-19,ten,REF", "line 2: invalid amount"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCSVStatement(strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseMT940Statement(t *testing.T) {
+	input := strings.Join([]string{
+		":20:STATEMENT1",
+		":25:1234567890",
+		":28C:1/1",
+		":60F:C231018IDR0,00",
+		":61:2310191019C1500,00NTRFLOAN-001//BANK-1",
+		":86:Payment from",
+		"JOHN DOE",
+		":61:231020D250,NTRFNONREF//BANK-2",
+		":86:Charge",
+		":61:231021RC10,5NTRFLOAN-002",
+		":62F:C231021IDR1239,50",
+	}, "\r\n")
+
+	statement, err := ParseMT940Statement(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseMT940Statement error: %v", err)
+	}
+
+	if statement.AccountNumber != "1234567890" {
+		t.Errorf("AccountNumber = %q, want 1234567890", statement.AccountNumber)
+	}
+
+	want := []StatementLine{
+		{LineNumber: 5, ValueDate: time.Date(2023, 10, 19, 0, 0, 0, 0, time.UTC), Direction: "CREDIT", Amount: 1500, Reference: "LOAN-001", Description: "Payment from JOHN DOE"},
+		{LineNumber: 8, ValueDate: time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC), Direction: "DEBIT", Amount: 250, Reference: "BANK-2", Description: "Charge"},
+		{LineNumber: 10, ValueDate: time.Date(2023, 10, 21, 0, 0, 0, 0, time.UTC), Direction: "DEBIT", Amount: 10.5, Reference: "LOAN-002"},
+	}
+	if len(statement.Lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(statement.Lines), len(want), statement.Lines)
+	}
+	for i, line := range statement.Lines {
+		if line != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, line, want[i])
+		}
+	}
+}
+
+func TestParseMT940StatementRejectsBadLine(t *testing.T) {
+	input := ":25:1234567890\n:61:not a statement line\n"
+	_, err := ParseMT940Statement(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 2: invalid :61: statement line") {
+		t.Errorf("error = %v, want invalid :61: on line 2", err)
+	}
+}
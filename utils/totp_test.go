@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA-1 test key "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFCVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d) error: %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTPStepBoundaries(t *testing.T) {
+	// 1111111110 is the first second of step 37037037.
+	stepStart := time.Unix(1111111110, 0)
+	step := TOTPStep(stepStart)
+	code, _ := TOTPCode(rfcSecret, step)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"first second of step", stepStart, true},
+		{"last second of step", stepStart.Add(29 * time.Second), true},
+		{"one step early", stepStart.Add(-TOTPPeriod * time.Second), true},
+		{"one step late", stepStart.Add(2*TOTPPeriod*time.Second - time.Second), true},
+		{"two steps early", stepStart.Add(-TOTPPeriod*time.Second - time.Second), false},
+		{"two steps late", stepStart.Add(2 * TOTPPeriod * time.Second), false},
+	}
+
+	for _, tt := range tests {
+		matched, ok := ValidateTOTP(rfcSecret, code, tt.at, 0)
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+		if ok && matched != step {
+			t.Errorf("%s: matched step %d, want %d", tt.name, matched, step)
+		}
+	}
+}
+
+func TestValidateTOTPRefusesReplay(t *testing.T) {
+	at := time.Unix(1234567890, 0)
+	step := TOTPStep(at)
+	code, _ := TOTPCode(rfcSecret, step)
+
+	matched, ok := ValidateTOTP(rfcSecret, code, at, step-1)
+	if !ok || matched != step {
+		t.Fatalf("first use: got (%d, %v), want (%d, true)", matched, ok, step)
+	}
+
+	if _, ok := ValidateTOTP(rfcSecret, code, at, matched); ok {
+		t.Error("code accepted again after its step was recorded")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, code, at.Add(TOTPPeriod*time.Second), matched); ok {
+		t.Error("code accepted in the next step after its step was recorded")
+	}
+
+	// An older step that was never used is still refused once a later step
+	// has been consumed.
+	previous, _ := TOTPCode(rfcSecret, step-1)
+	if _, ok := ValidateTOTP(rfcSecret, previous, at, step); ok {
+		t.Error("older code accepted after a later step was recorded")
+	}
+}
+
+func TestValidateTOTPRejectsMalformedCodes(t *testing.T) {
+	at := time.Unix(1234567890, 0)
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := ValidateTOTP(rfcSecret, code, at, 0); ok {
+			t.Errorf("ValidateTOTP accepted %q", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "123456", at, 0); ok {
+		t.Error("ValidateTOTP accepted a code for an invalid secret")
+	}
+}
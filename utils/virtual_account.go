@@ -0,0 +1,13 @@
+package utils
+
+import "fmt"
+
+// GenerateVANumber builds a VA number from the bank's company prefix, a type
+// digit (1 for a loan, 2 for a borrower) and the zero-padded owner ID.
+func GenerateVANumber(prefix string, isLoan bool, ownerID uint64) string {
+	typeDigit := 2
+	if isLoan {
+		typeDigit = 1
+	}
+	return fmt.Sprintf("%s%d%09d", prefix, typeDigit, ownerID)
+}